@@ -0,0 +1,220 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: cache_delta.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	CacheDelta_GetSecret_FullMethodName          = "/apiserver.v1.CacheDelta/GetSecret"
+	CacheDelta_ListUserPolicies_FullMethodName   = "/apiserver.v1.CacheDelta/ListUserPolicies"
+	CacheDelta_ListUserAttributes_FullMethodName = "/apiserver.v1.CacheDelta/ListUserAttributes"
+	CacheDelta_GetRevision_FullMethodName        = "/apiserver.v1.CacheDelta/GetRevision"
+)
+
+// CacheDeltaClient is the client API for CacheDelta service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CacheDeltaClient interface {
+	GetSecret(ctx context.Context, in *GetSecretRequest, opts ...grpc.CallOption) (*GetSecretResponse, error)
+	ListUserPolicies(ctx context.Context, in *ListUserPoliciesRequest, opts ...grpc.CallOption) (*ListUserPoliciesResponse, error)
+	ListUserAttributes(ctx context.Context, in *ListUserAttributesRequest, opts ...grpc.CallOption) (*ListUserAttributesResponse, error)
+	GetRevision(ctx context.Context, in *GetRevisionRequest, opts ...grpc.CallOption) (*GetRevisionResponse, error)
+}
+
+type cacheDeltaClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCacheDeltaClient(cc grpc.ClientConnInterface) CacheDeltaClient {
+	return &cacheDeltaClient{cc}
+}
+
+func (c *cacheDeltaClient) GetSecret(ctx context.Context, in *GetSecretRequest, opts ...grpc.CallOption) (*GetSecretResponse, error) {
+	out := new(GetSecretResponse)
+	err := c.cc.Invoke(ctx, CacheDelta_GetSecret_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheDeltaClient) ListUserPolicies(ctx context.Context, in *ListUserPoliciesRequest, opts ...grpc.CallOption) (*ListUserPoliciesResponse, error) {
+	out := new(ListUserPoliciesResponse)
+	err := c.cc.Invoke(ctx, CacheDelta_ListUserPolicies_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheDeltaClient) ListUserAttributes(ctx context.Context, in *ListUserAttributesRequest, opts ...grpc.CallOption) (*ListUserAttributesResponse, error) {
+	out := new(ListUserAttributesResponse)
+	err := c.cc.Invoke(ctx, CacheDelta_ListUserAttributes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheDeltaClient) GetRevision(ctx context.Context, in *GetRevisionRequest, opts ...grpc.CallOption) (*GetRevisionResponse, error) {
+	out := new(GetRevisionResponse)
+	err := c.cc.Invoke(ctx, CacheDelta_GetRevision_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CacheDeltaServer is the server API for CacheDelta service.
+// All implementations must embed UnimplementedCacheDeltaServer
+// for forward compatibility
+type CacheDeltaServer interface {
+	GetSecret(context.Context, *GetSecretRequest) (*GetSecretResponse, error)
+	ListUserPolicies(context.Context, *ListUserPoliciesRequest) (*ListUserPoliciesResponse, error)
+	ListUserAttributes(context.Context, *ListUserAttributesRequest) (*ListUserAttributesResponse, error)
+	GetRevision(context.Context, *GetRevisionRequest) (*GetRevisionResponse, error)
+	mustEmbedUnimplementedCacheDeltaServer()
+}
+
+// UnimplementedCacheDeltaServer must be embedded to have forward compatible implementations.
+type UnimplementedCacheDeltaServer struct {
+}
+
+func (UnimplementedCacheDeltaServer) GetSecret(context.Context, *GetSecretRequest) (*GetSecretResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSecret not implemented")
+}
+func (UnimplementedCacheDeltaServer) ListUserPolicies(context.Context, *ListUserPoliciesRequest) (*ListUserPoliciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserPolicies not implemented")
+}
+func (UnimplementedCacheDeltaServer) ListUserAttributes(context.Context, *ListUserAttributesRequest) (*ListUserAttributesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserAttributes not implemented")
+}
+func (UnimplementedCacheDeltaServer) GetRevision(context.Context, *GetRevisionRequest) (*GetRevisionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRevision not implemented")
+}
+func (UnimplementedCacheDeltaServer) mustEmbedUnimplementedCacheDeltaServer() {}
+
+// UnsafeCacheDeltaServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CacheDeltaServer will
+// result in compilation errors.
+type UnsafeCacheDeltaServer interface {
+	mustEmbedUnimplementedCacheDeltaServer()
+}
+
+func RegisterCacheDeltaServer(s grpc.ServiceRegistrar, srv CacheDeltaServer) {
+	s.RegisterService(&CacheDelta_ServiceDesc, srv)
+}
+
+func _CacheDelta_GetSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheDeltaServer).GetSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheDelta_GetSecret_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheDeltaServer).GetSecret(ctx, req.(*GetSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheDelta_ListUserPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserPoliciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheDeltaServer).ListUserPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheDelta_ListUserPolicies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheDeltaServer).ListUserPolicies(ctx, req.(*ListUserPoliciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheDelta_ListUserAttributes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserAttributesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheDeltaServer).ListUserAttributes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheDelta_ListUserAttributes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheDeltaServer).ListUserAttributes(ctx, req.(*ListUserAttributesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CacheDelta_GetRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheDeltaServer).GetRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CacheDelta_GetRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheDeltaServer).GetRevision(ctx, req.(*GetRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CacheDelta_ServiceDesc is the grpc.ServiceDesc for CacheDelta service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CacheDelta_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "apiserver.v1.CacheDelta",
+	HandlerType: (*CacheDeltaServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSecret",
+			Handler:    _CacheDelta_GetSecret_Handler,
+		},
+		{
+			MethodName: "ListUserPolicies",
+			Handler:    _CacheDelta_ListUserPolicies_Handler,
+		},
+		{
+			MethodName: "ListUserAttributes",
+			Handler:    _CacheDelta_ListUserAttributes_Handler,
+		},
+		{
+			MethodName: "GetRevision",
+			Handler:    _CacheDelta_GetRevision_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cache_delta.proto",
+}
@@ -0,0 +1,146 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: authz.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Authz_Authorize_FullMethodName      = "/authzserver.v1.Authz/Authorize"
+	Authz_BatchAuthorize_FullMethodName = "/authzserver.v1.Authz/BatchAuthorize"
+)
+
+// AuthzClient is the client API for Authz service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AuthzClient interface {
+	Authorize(ctx context.Context, in *AuthorizeRequest, opts ...grpc.CallOption) (*AuthorizeResponse, error)
+	BatchAuthorize(ctx context.Context, in *BatchAuthorizeRequest, opts ...grpc.CallOption) (*BatchAuthorizeResponse, error)
+}
+
+type authzClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAuthzClient(cc grpc.ClientConnInterface) AuthzClient {
+	return &authzClient{cc}
+}
+
+func (c *authzClient) Authorize(ctx context.Context, in *AuthorizeRequest, opts ...grpc.CallOption) (*AuthorizeResponse, error) {
+	out := new(AuthorizeResponse)
+	err := c.cc.Invoke(ctx, Authz_Authorize_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authzClient) BatchAuthorize(ctx context.Context, in *BatchAuthorizeRequest, opts ...grpc.CallOption) (*BatchAuthorizeResponse, error) {
+	out := new(BatchAuthorizeResponse)
+	err := c.cc.Invoke(ctx, Authz_BatchAuthorize_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuthzServer is the server API for Authz service.
+// All implementations must embed UnimplementedAuthzServer
+// for forward compatibility
+type AuthzServer interface {
+	Authorize(context.Context, *AuthorizeRequest) (*AuthorizeResponse, error)
+	BatchAuthorize(context.Context, *BatchAuthorizeRequest) (*BatchAuthorizeResponse, error)
+	mustEmbedUnimplementedAuthzServer()
+}
+
+// UnimplementedAuthzServer must be embedded to have forward compatible implementations.
+type UnimplementedAuthzServer struct {
+}
+
+func (UnimplementedAuthzServer) Authorize(context.Context, *AuthorizeRequest) (*AuthorizeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Authorize not implemented")
+}
+func (UnimplementedAuthzServer) BatchAuthorize(context.Context, *BatchAuthorizeRequest) (*BatchAuthorizeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchAuthorize not implemented")
+}
+func (UnimplementedAuthzServer) mustEmbedUnimplementedAuthzServer() {}
+
+// UnsafeAuthzServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AuthzServer will
+// result in compilation errors.
+type UnsafeAuthzServer interface {
+	mustEmbedUnimplementedAuthzServer()
+}
+
+func RegisterAuthzServer(s grpc.ServiceRegistrar, srv AuthzServer) {
+	s.RegisterService(&Authz_ServiceDesc, srv)
+}
+
+func _Authz_Authorize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthorizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthzServer).Authorize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Authz_Authorize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthzServer).Authorize(ctx, req.(*AuthorizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Authz_BatchAuthorize_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchAuthorizeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthzServer).BatchAuthorize(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Authz_BatchAuthorize_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthzServer).BatchAuthorize(ctx, req.(*BatchAuthorizeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Authz_ServiceDesc is the grpc.ServiceDesc for Authz service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Authz_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "authzserver.v1.Authz",
+	HandlerType: (*AuthzServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Authorize",
+			Handler:    _Authz_Authorize_Handler,
+		},
+		{
+			MethodName: "BatchAuthorize",
+			Handler:    _Authz_BatchAuthorize_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "authz.proto",
+}
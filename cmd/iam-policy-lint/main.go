@@ -0,0 +1,10 @@
+// iam-policy-lint analyzes ladon policies and reports the issues found before the policies are saved.
+package main
+
+import (
+	"github.com/nico612/iam-demo/internal/policylint"
+)
+
+func main() {
+	policylint.NewApp("iam-policy-lint").Run()
+}
@@ -0,0 +1,21 @@
+-- 租户迁移：新增 tenant 表，user 表新增 tenant 列，用户名改为在租户内唯一.
+-- 已有用户归属默认租户 default，默认租户不需要在 tenant 表中创建.
+
+CREATE TABLE IF NOT EXISTS `tenant` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `instanceID` varchar(32) DEFAULT NULL,
+  `name` varchar(64) NOT NULL,
+  `displayName` varchar(64) NOT NULL DEFAULT '',
+  `description` varchar(255) NOT NULL DEFAULT '',
+  `extendShadow` longtext DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+  `updatedAt` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_name` (`name`),
+  UNIQUE KEY `instanceID_UNIQUE` (`instanceID`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+ALTER TABLE `user`
+  ADD COLUMN `tenant` varchar(64) NOT NULL DEFAULT 'default' AFTER `instanceID`,
+  DROP INDEX `idx_name`,
+  ADD UNIQUE KEY `idx_tenant_name` (`tenant`, `name`);
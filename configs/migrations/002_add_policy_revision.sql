@@ -0,0 +1,23 @@
+-- 策略版本迁移：新增 policy_revision 表，策略的每次创建、更新和回滚都会追加一个版本.
+-- 已有的策略没有版本记录，迁移时以当前的 policyShadow 补录版本 1，作者为策略所属用户，
+-- 之后的修改从版本 2 开始，并且可以回滚到迁移时的内容.
+
+CREATE TABLE IF NOT EXISTS `policy_revision` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT,
+  `username` varchar(255) NOT NULL,
+  `policyName` varchar(255) NOT NULL,
+  `version` bigint(20) NOT NULL,
+  `author` varchar(255) NOT NULL DEFAULT '',
+  `message` varchar(255) NOT NULL DEFAULT '',
+  `policyShadow` longtext DEFAULT NULL,
+  `createdAt` timestamp NOT NULL DEFAULT current_timestamp(),
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_policy_version` (`username`, `policyName`, `version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+INSERT INTO `policy_revision` (`username`, `policyName`, `version`, `author`, `message`, `policyShadow`, `createdAt`)
+SELECT p.`username`, p.`name`, 1, p.`username`, 'Initial revision', p.`policyShadow`, p.`createdAt`
+FROM `policy` p
+WHERE NOT EXISTS (
+  SELECT 1 FROM `policy_revision` r WHERE r.`username` = p.`username` AND r.`policyName` = p.`name`
+);
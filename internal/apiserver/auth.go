@@ -6,10 +6,15 @@ import (
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/core"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	srvv1 "github.com/nico612/iam-demo/internal/apiserver/service/v1"
 	"github.com/nico612/iam-demo/internal/apiserver/store"
+	"github.com/nico612/iam-demo/internal/pkg/code"
 	"github.com/nico612/iam-demo/internal/pkg/middleware"
 	"github.com/nico612/iam-demo/internal/pkg/middleware/auth"
+	genericoptions "github.com/nico612/iam-demo/internal/pkg/options"
 	"github.com/nico612/iam-demo/pkg/log"
 	"github.com/spf13/viper"
 	"net/http"
@@ -30,6 +35,19 @@ type loginInfo struct {
 	Password string `form:"password" json:"password" binding:"required,password"`
 }
 
+// sessionIDKey defines the key in gin context which carries the session created by a login.
+const sessionIDKey = "sessionID"
+
+// loginUser is passed from authenticator to payloadFunc, it binds the user with the session of the login.
+type loginUser struct {
+	*v1.User
+	SessionID string
+}
+
+type refreshInfo struct {
+	RefreshToken string `form:"refreshToken" json:"refreshToken" binding:"required"`
+}
+
 func newBasicAuth() middleware.AuthStrategy {
 	return auth.NewBasicStrategy(func(username string, password string) bool {
 		user, err := store.Client().Users().Get(context.TODO(), username, metav1.GetOptions{})
@@ -81,7 +99,71 @@ func newJWTAuth() middleware.AuthStrategy {
 		//HTTPStatusMessageFunc: nil,
 	})
 
-	return auth.NewJWTStrategy(*ginjwt)
+	return auth.NewJWTStrategy(*ginjwt).WithSessionValidator(validateSession())
+}
+
+func newAPIKeyAuth() middleware.AuthStrategy {
+	return auth.NewAPIKeyStrategy(func(token string) (auth.APIKey, error) {
+		key, err := srvv1.NewService(store.Client()).APIKeys().Get(context.TODO(), token)
+		if err != nil {
+			return auth.APIKey{}, err
+		}
+
+		return auth.APIKey{
+			ID:       key.ID,
+			Username: key.Username,
+			Expires:  key.Expires,
+		}, nil
+	})
+}
+
+// newCertAuth maps the identity of a client certificate to an existing iam user with the explicit mapping
+// of secure.client-cert.users. Machine clients (service accounts) are registered as ordinary users.
+func newCertAuth(opts *genericoptions.ClientCertAuthenticationOptions) middleware.AuthStrategy {
+	mapping := auth.NewCertUserMapping(opts.UserMapping())
+
+	return auth.NewCertStrategy(opts.Identity, func(identity string) (string, error) {
+		username, err := mapping(identity)
+		if err != nil {
+			return "", err
+		}
+
+		user, err := store.Client().Users().Get(context.TODO(), username, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+
+		return user.Name, nil
+	})
+}
+
+// newAuthChain builds the authentication chain from the ordered strategy names.
+func newAuthChain(names []string, clientCert *genericoptions.ClientCertAuthenticationOptions) middleware.AuthStrategy {
+	strategies := map[string]func() middleware.AuthStrategy{
+		auth.StrategyBasic:  newBasicAuth,
+		auth.StrategyJWT:    newJWTAuth,
+		auth.StrategyAPIKey: newAPIKeyAuth,
+		auth.StrategyMTLS: func() middleware.AuthStrategy {
+			return newCertAuth(clientCert)
+		},
+	}
+
+	authenticators := make([]auth.NamedAuthenticator, 0, len(names))
+	for _, name := range names {
+		newStrategy, ok := strategies[name]
+		if !ok {
+			log.Fatalf("authentication strategy `%s` is not supported by iam-apiserver", name)
+		}
+
+		authenticator, ok := newStrategy().(auth.Authenticator)
+		if !ok {
+			log.Fatalf("authentication strategy `%s` can not take part in an authentication chain", name)
+		}
+
+		authenticators = append(authenticators, auth.NamedAuthenticator{Name: name, Authenticator: authenticator})
+	}
+
+	return auth.NewChainStrategy(authenticators...)
 }
 
 // 登录认证
@@ -114,7 +196,21 @@ func authenticator() func(c *gin.Context) (interface{}, error) {
 		user.LoginedAt = time.Now()
 		_ = store.Client().Users().Update(c, user, metav1.UpdateOptions{})
 
-		return user, nil
+		// 每次登录创建一个 session，session id 同时作为 refresh token family
+		session := &srvv1.Session{
+			Username:  user.Name,
+			UserAgent: c.Request.UserAgent(),
+			ClientIP:  c.ClientIP(),
+		}
+		if err := srvv1.NewService(store.Client()).Sessions().Create(c, session, viper.GetDuration("jwt.max-refresh")); err != nil {
+			return "", err
+		}
+
+		// loginResponse 需要用户名和 session 来签发 refresh token
+		c.Set(middleware.UsernameKey, user.Name)
+		c.Set(sessionIDKey, session.ID)
+
+		return &loginUser{User: user, SessionID: session.ID}, nil
 	}
 }
 
@@ -157,21 +253,111 @@ func parseWithBody(c *gin.Context) (loginInfo, error) {
 	return login, nil
 }
 
-func refreshResponse() func(c *gin.Context, code int, token string, expire time.Time) {
-	return func(c *gin.Context, code int, token string, expire time.Time) {
+// refreshHandler exchanges a refresh token for a new access token, the refresh token is rotated on every call.
+func refreshHandler(j auth.JWTStrategy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var r refreshInfo
+		if err := c.ShouldBindJSON(&r); err != nil {
+			core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+			return
+		}
+
+		ttl := viper.GetDuration("jwt.max-refresh")
+		srv := srvv1.NewService(store.Client())
+
+		rt, refreshToken, refreshExpire, err := srv.RefreshTokens().Rotate(c, r.RefreshToken, ttl)
+		if err != nil {
+			core.WriteResponse(c, err, nil)
+
+			return
+		}
+
+		// refresh token family 就是 session id，session 被吊销后不能再刷新
+		session, err := srv.Sessions().Get(c, rt.Family)
+		if err != nil {
+			srv.RefreshTokens().RevokeFamily(c, rt.Family)
+			core.WriteResponse(c, errors.WithCode(code.ErrTokenInvalid, "session has been revoked"), nil)
+
+			return
+		}
+		_ = srv.Sessions().Touch(c, session, ttl)
+
+		token, expire, err := j.TokenGenerator(&loginUser{
+			User:      &v1.User{ObjectMeta: metav1.ObjectMeta{Name: rt.Username}},
+			SessionID: session.ID,
+		})
+		if err != nil {
+			core.WriteResponse(c, errors.WithCode(code.ErrUnknown, err.Error()), nil)
+
+			return
+		}
+
+		if j.SendCookie {
+			c.SetCookie(j.CookieName, token, int(time.Until(expire).Seconds()), "/",
+				j.CookieDomain, j.SecureCookie, j.CookieHTTPOnly)
+		}
+
+		refreshResponse()(c, token, expire, refreshToken, refreshExpire)
+	}
+}
+
+// logoutHandler revokes the session of the access token together with its refresh token family, then clears the
+// cookie like GinJWTMiddleware.LogoutHandler. An expired access token can not tell its session, so the refresh
+// token may be passed in the body to revoke the session it belongs to.
+func logoutHandler(j auth.JWTStrategy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		srv := srvv1.NewService(store.Client())
+
+		if claims, err := j.GetClaimsFromJWT(c); err == nil {
+			username, _ := claims[jwt.IdentityKey].(string)
+			if sid, _ := claims[auth.SessionClaim].(string); username != "" && sid != "" {
+				_ = srv.Sessions().Delete(c, username, sid)
+				srv.RefreshTokens().RevokeFamily(c, sid)
+			}
+		}
+
+		var r refreshInfo
+		if err := c.ShouldBindJSON(&r); err == nil {
+			if rt, err := srv.RefreshTokens().Get(c, r.RefreshToken); err == nil {
+				_ = srv.Sessions().Delete(c, rt.Username, rt.Family)
+				srv.RefreshTokens().RevokeFamily(c, rt.Family)
+			}
+		}
+
+		j.LogoutHandler(c)
+	}
+}
+
+func refreshResponse() func(c *gin.Context, token string, expire time.Time, refreshToken string, refreshExpire time.Time) {
+	return func(c *gin.Context, token string, expire time.Time, refreshToken string, refreshExpire time.Time) {
 		c.JSON(http.StatusOK, gin.H{
-			"token":  token,
-			"expire": expire.Format(time.RFC3339),
+			"token":         token,
+			"expire":        expire.Format(time.RFC3339),
+			"refreshToken":  refreshToken,
+			"refreshExpire": refreshExpire.Format(time.RFC3339),
 		})
 	}
 }
 
 func loginResponse() func(c *gin.Context, code int, token string, expire time.Time) {
 	return func(c *gin.Context, code int, token string, expire time.Time) {
-		c.JSON(http.StatusOK, gin.H{
+		resp := gin.H{
 			"token":  token,
 			"expire": expire.Format(time.RFC3339),
-		})
+		}
+
+		// 每次登录都会创建一个新的 refresh token family，family id 就是 session id
+		refreshToken, refreshExpire, err := srvv1.NewService(store.Client()).RefreshTokens().
+			Create(c, c.GetString(middleware.UsernameKey), c.GetString(sessionIDKey), viper.GetDuration("jwt.max-refresh"))
+		if err != nil {
+			log.L(c).Errorf("issue refresh token failed: %s", err.Error())
+		} else {
+			resp["refreshToken"] = refreshToken
+			resp["refreshExpire"] = refreshExpire.Format(time.RFC3339)
+		}
+
+		c.JSON(http.StatusOK, resp)
 	}
 }
 
@@ -182,9 +368,17 @@ func payloadFunc() func(data interface{}) jwt.MapClaims {
 			"aud": APIServerAudience,
 		}
 
-		if u, ok := data.(v1.User); ok {
+		switch u := data.(type) {
+		case v1.User:
+			claims[jwt.IdentityKey] = u.Name
+			claims["sub"] = u.Name
+		case *v1.User:
+			claims[jwt.IdentityKey] = u.Name
+			claims["sub"] = u.Name
+		case *loginUser:
 			claims[jwt.IdentityKey] = u.Name
 			claims["sub"] = u.Name
+			claims[auth.SessionClaim] = u.SessionID
 		}
 
 		return claims
@@ -192,6 +386,25 @@ func payloadFunc() func(data interface{}) jwt.MapClaims {
 
 }
 
+// validateSession makes sure the session of the token has not been revoked.
+func validateSession() func(c *gin.Context, username, sid string) error {
+	return func(c *gin.Context, username, sid string) error {
+		if sid == "" {
+			return errors.WithCode(code.ErrTokenInvalid, "token is not bound to a session.")
+		}
+
+		sessions := srvv1.NewService(store.Client()).Sessions()
+		session, err := sessions.Get(c, sid)
+		if err != nil || session.Username != username {
+			return errors.WithCode(code.ErrTokenInvalid, "session has been revoked.")
+		}
+
+		_ = sessions.Touch(c, session, 0)
+
+		return nil
+	}
+}
+
 func authorizator() func(data interface{}, c *gin.Context) bool {
 	return func(data interface{}, c *gin.Context) bool {
 		if v, ok := data.(string); ok {
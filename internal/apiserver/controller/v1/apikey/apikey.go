@@ -0,0 +1,16 @@
+package apikey
+
+import (
+	srvv1 "github.com/nico612/iam-demo/internal/apiserver/service/v1"
+	"github.com/nico612/iam-demo/internal/apiserver/store"
+)
+
+// APIKeyController create a api key handler used to handle request for api key resource.
+type APIKeyController struct {
+	srv srvv1.Service
+}
+
+// NewAPIKeyController creates a api key handler.
+func NewAPIKeyController(store store.Factory) *APIKeyController {
+	return &APIKeyController{srv: srvv1.NewService(store)}
+}
@@ -0,0 +1,50 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	srvv1 "github.com/nico612/iam-demo/internal/apiserver/service/v1"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+type createRequest struct {
+	Description string `json:"description"`
+	Expires     int64  `json:"expires"`
+}
+
+// Create mints a new api key for the user. The plaintext key is only returned in this response.
+func (a *APIKeyController) Create(c *gin.Context) {
+	log.L(c).Info("create api key function called.")
+
+	var r createRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if r.Expires != 0 && r.Expires <= time.Now().Unix() {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, "expires must be a future unix timestamp"), nil)
+
+		return
+	}
+
+	key := &srvv1.APIKey{
+		Username:    c.Param("name"),
+		Description: r.Description,
+		Expires:     r.Expires,
+	}
+
+	if err := a.srv.APIKeys().Create(c, key); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, key)
+}
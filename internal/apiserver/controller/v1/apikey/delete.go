@@ -0,0 +1,21 @@
+package apikey
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// Delete revokes an api key by its id.
+func (a *APIKeyController) Delete(c *gin.Context) {
+	log.L(c).Info("delete api key function called.")
+
+	if err := a.srv.APIKeys().Delete(c, c.Param("name"), c.Param("id")); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
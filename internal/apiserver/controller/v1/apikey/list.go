@@ -0,0 +1,22 @@
+package apikey
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// List returns the api keys owned by the user, without the plaintext keys.
+func (a *APIKeyController) List(c *gin.Context) {
+	log.L(c).Info("list api key function called.")
+
+	keys, err := a.srv.APIKeys().List(c, c.Param("name"))
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, keys)
+}
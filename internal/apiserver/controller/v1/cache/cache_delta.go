@@ -0,0 +1,201 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/AlekSi/pointer"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	deltapb "github.com/nico612/iam-demo/api/proto/apiserver/v1"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// CacheDelta serves the secrets and policies affected by a change notification, and the user attributes.
+type CacheDelta struct {
+	deltapb.UnimplementedCacheDeltaServer
+
+	cache *Cache
+}
+
+// NewCacheDelta creates the cache delta service on top of the cache service.
+func NewCacheDelta(cache *Cache) *CacheDelta {
+	return &CacheDelta{cache: cache}
+}
+
+// GetSecret returns the secret of the user with the given secret id.
+func (d *CacheDelta) GetSecret(ctx context.Context, r *deltapb.GetSecretRequest) (*deltapb.GetSecretResponse, error) {
+	log.L(ctx).Info("get secret function called.")
+
+	// secret 按名称存储，secret id 不是查询条件，用户的 secret 数量有限，这里直接过滤
+	secrets, err := d.cache.store.Secrets().List(ctx, r.Username, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	for _, secret := range secrets.Items {
+		if secret.SecretID != r.SecretId {
+			continue
+		}
+
+		return &deltapb.GetSecretResponse{
+			Secret: &deltapb.SecretInfo{
+				Name:        secret.Name,
+				SecretId:    secret.SecretID,
+				Username:    secret.Username,
+				SecretKey:   secret.SecretKey,
+				Expires:     secret.Expires,
+				Description: secret.Description,
+				CreatedAt:   secret.CreatedAt.Format("2006-01-02 15:04:05"),
+				UpdatedAt:   secret.UpdatedAt.Format("2006-01-02 15:04:05"),
+			},
+		}, nil
+	}
+
+	return nil, status.Errorf(codes.NotFound, "secret %s of user %s not found", r.SecretId, r.Username)
+}
+
+// ListUserPolicies returns all the policies of the user.
+func (d *CacheDelta) ListUserPolicies(
+	ctx context.Context,
+	r *deltapb.ListUserPoliciesRequest,
+) (*deltapb.ListUserPoliciesResponse, error) {
+	log.L(ctx).Info("list user policies function called.")
+
+	// 不能使用空用户名，否则会返回所有用户的策略
+	if r.Username == "" {
+		return nil, status.Error(codes.InvalidArgument, "username is required")
+	}
+
+	policies, err := d.cache.store.Policies().List(ctx, r.Username, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	items := make([]*deltapb.PolicyInfo, 0, len(policies.Items))
+	for _, policy := range policies.Items {
+		items = append(items, &deltapb.PolicyInfo{
+			Name:         policy.Name,
+			Username:     policy.Username,
+			PolicyShadow: policy.PolicyShadow,
+			CreatedAt:    policy.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return &deltapb.ListUserPoliciesResponse{
+		TotalCount: policies.TotalCount,
+		Items:      items,
+	}, nil
+}
+
+// ListUserAttributes returns the `Extend` data of the user, or of all the users when no username is given.
+func (d *CacheDelta) ListUserAttributes(
+	ctx context.Context,
+	r *deltapb.ListUserAttributesRequest,
+) (*deltapb.ListUserAttributesResponse, error) {
+	log.L(ctx).Info("list user attributes function called.")
+
+	var users []*v1.User
+
+	if r.Username != "" {
+		user, err := d.cache.store.Users().Get(ctx, r.Username, metav1.GetOptions{})
+		if err != nil && !errors.IsCode(err, code.ErrUserNotFound) {
+			return nil, err
+		}
+
+		if user != nil {
+			users = append(users, user)
+		}
+	} else {
+		list, err := d.cache.store.Users().List(ctx, metav1.ListOptions{Limit: pointer.ToInt64(-1)})
+		if err != nil {
+			return nil, errors.WithCode(code.ErrDatabase, err.Error())
+		}
+
+		users = list.Items
+	}
+
+	attributes := make(map[string]*structpb.Struct, len(users))
+	for _, user := range users {
+		if len(user.Extend) == 0 {
+			continue
+		}
+
+		value, err := structpb.NewStruct(user.Extend)
+		if err != nil {
+			log.L(ctx).Warnf("failed to encode attributes of user %s: %s", user.Name, err.Error())
+
+			continue
+		}
+
+		attributes[user.Name] = value
+	}
+
+	return &deltapb.ListUserAttributesResponse{Attributes: attributes}, nil
+}
+
+// GetRevision returns a checksum of all the secrets, policies and user attributes. iam-authz-server compares it
+// with the revision of its last full load, and only reloads when they differ.
+func (d *CacheDelta) GetRevision(
+	ctx context.Context,
+	_ *deltapb.GetRevisionRequest,
+) (*deltapb.GetRevisionResponse, error) {
+	log.L(ctx).Info("get revision function called.")
+
+	opts := metav1.ListOptions{Limit: pointer.ToInt64(-1)}
+
+	secrets, err := d.cache.store.Secrets().List(ctx, "", opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	policies, err := d.cache.store.Policies().List(ctx, "", opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	users, err := d.cache.store.Users().List(ctx, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	// 每个条目生成一行，排序后计算摘要，保证结果和查询顺序无关
+	entries := make([]string, 0, len(secrets.Items)+len(policies.Items)+len(users.Items))
+	for _, secret := range secrets.Items {
+		entries = append(entries, fmt.Sprintf("secret\x00%s\x00%s\x00%s\x00%d",
+			secret.Username, secret.SecretID, secret.SecretKey, secret.Expires))
+	}
+
+	for _, policy := range policies.Items {
+		entries = append(entries, fmt.Sprintf("policy\x00%s\x00%s\x00%s", policy.Username, policy.Name, policy.PolicyShadow))
+	}
+
+	for _, user := range users.Items {
+		if len(user.Extend) == 0 {
+			continue
+		}
+
+		extend, _ := json.Marshal(user.Extend)
+		entries = append(entries, fmt.Sprintf("user\x00%s\x00%s", user.Name, extend))
+	}
+
+	sort.Strings(entries)
+
+	h := sha256.New()
+	for _, entry := range entries {
+		h.Write([]byte(entry))
+		h.Write([]byte{'\n'})
+	}
+
+	return &deltapb.GetRevisionResponse{Revision: hex.EncodeToString(h.Sum(nil))}, nil
+}
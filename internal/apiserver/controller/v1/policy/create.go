@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// Create creates a new ladon policy for the authenticated user, given as ladon JSON or as the policy DSL.
+// It will convert the policy to string and store it in the storage.
+func (p *PolicyController) Create(c *gin.Context) {
+	log.L(c).Info("create policy function called.")
+
+	var source PolicySource
+	if err := c.ShouldBindJSON(&source); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if err := source.compile(); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	r := source.Policy
+
+	if errs := r.Validate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+
+		return
+	}
+
+	r.Username = c.GetString(middleware.UsernameKey)
+
+	if !p.lintChange(c, &r) {
+		return
+	}
+
+	if err := p.srv.Policies().Create(c, &r, metav1.CreateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, r)
+}
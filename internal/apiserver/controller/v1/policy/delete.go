@@ -0,0 +1,38 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// Delete deletes the policy of the authenticated user by the policy name.
+func (p *PolicyController) Delete(c *gin.Context) {
+	log.L(c).Info("delete policy function called.")
+
+	if err := p.srv.Policies().Delete(c, c.GetString(middleware.UsernameKey), c.Param("name"),
+		metav1.DeleteOptions{Unscoped: true}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
+
+// DeleteCollection batch deletes the policies of the authenticated user by the policy names.
+func (p *PolicyController) DeleteCollection(c *gin.Context) {
+	log.L(c).Info("batch delete policy function called.")
+
+	if err := p.srv.Policies().DeleteCollection(c, c.GetString(middleware.UsernameKey), c.QueryArray("name"),
+		metav1.DeleteOptions{Unscoped: true}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,39 @@
+package policy
+
+import (
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/pkg/policydsl"
+)
+
+// PolicySource is a policy given either as ladon JSON in `policy`, or as the policy DSL in `dsl`.
+// The policy is always stored as ladon JSON.
+type PolicySource struct {
+	v1.Policy
+
+	DSL string `json:"dsl,omitempty"`
+}
+
+// compile replaces the ladon policy with the one compiled from the DSL, when the DSL is given.
+func (s *PolicySource) compile() error {
+	if s.DSL == "" {
+		return nil
+	}
+
+	ladonPolicy := s.Policy.Policy
+	if ladonPolicy.Effect != "" || len(ladonPolicy.Subjects) > 0 || len(ladonPolicy.Actions) > 0 ||
+		len(ladonPolicy.Resources) > 0 || len(ladonPolicy.Conditions) > 0 {
+		return errors.WithCode(code.ErrValidation, "policy and dsl can not be used together")
+	}
+
+	compiled, err := policydsl.Parse(s.DSL)
+	if err != nil {
+		return errors.WithCode(code.ErrValidation, "invalid dsl: %s", err.Error())
+	}
+
+	s.Policy.Policy = v1.AuthzPolicy{DefaultPolicy: *compiled}
+
+	return nil
+}
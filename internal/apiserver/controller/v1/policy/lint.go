@@ -0,0 +1,89 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// maxLintPolicies limits the number of policies of one lint request.
+const maxLintPolicies = 1000
+
+// LintRequest holds the policies to lint, the saved policies of the user are linted when it is empty.
+type LintRequest struct {
+	Policies []*authorization.LintPolicy `json:"policies"`
+}
+
+// Lint analyzes a policy set and returns the issues found, it never saves the policies.
+func (p *PolicyController) Lint(c *gin.Context) {
+	log.L(c).Info("lint policy function called.")
+
+	var r LintRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if len(r.Policies) > maxLintPolicies {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, "at most %d policies are allowed", maxLintPolicies), nil)
+
+		return
+	}
+
+	for _, policy := range r.Policies {
+		if policy == nil {
+			core.WriteResponse(c, errors.WithCode(code.ErrValidation, "policy can not be null"), nil)
+
+			return
+		}
+	}
+
+	result, err := p.srv.Policies().Lint(c, c.GetString(middleware.UsernameKey), r.Policies)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, result)
+}
+
+// lintChange lints the policy to save. It writes the error response and returns false when there are
+// error findings, the warning findings are returned in the `Warning` headers of the response.
+func (p *PolicyController) lintChange(c *gin.Context, policy *v1.Policy) bool {
+	result, err := p.srv.Policies().LintChange(c, policy)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return false
+	}
+
+	var errs []string
+	for _, finding := range result.Findings {
+		if finding.Severity == authorization.LintSeverityError {
+			errs = append(errs, finding.String())
+
+			continue
+		}
+
+		c.Writer.Header().Add("Warning", fmt.Sprintf("299 - %q", finding.String()))
+	}
+
+	if len(errs) > 0 {
+		core.WriteResponse(c, errors.WithCode(code.ErrPolicyLintFailed, strings.Join(errs, "; ")), nil)
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// DiffRevisionsRequest selects the two revisions to compare.
+type DiffRevisionsRequest struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to"   binding:"required,min=1"`
+}
+
+// ListRevisions returns the revisions of the policy of the authenticated user, the latest first.
+func (p *PolicyController) ListRevisions(c *gin.Context) {
+	log.L(c).Info("list policy revisions function called.")
+
+	var r metav1.ListOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	revisions, err := p.srv.Policies().ListRevisions(c, c.GetString(middleware.UsernameKey), c.Param("name"), r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, revisions)
+}
+
+// GetRevision returns a revision of the policy of the authenticated user by the version.
+func (p *PolicyController) GetRevision(c *gin.Context) {
+	log.L(c).Info("get policy revision function called.")
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	revision, err := p.srv.Policies().GetRevision(c, c.GetString(middleware.UsernameKey), c.Param("name"), version)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, revision)
+}
+
+// DiffRevisions compares two revisions of the policy of the authenticated user.
+func (p *PolicyController) DiffRevisions(c *gin.Context) {
+	log.L(c).Info("diff policy revisions function called.")
+
+	var r DiffRevisionsRequest
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	diff, err := p.srv.Policies().DiffRevisions(c, c.GetString(middleware.UsernameKey), c.Param("name"), r.From, r.To)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, diff)
+}
@@ -0,0 +1,41 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// RollbackRequest selects the revision to roll back to, with the message describing the change.
+type RollbackRequest struct {
+	Version int    `json:"version" binding:"required,min=1"`
+	Message string `json:"message" binding:"max=255"`
+}
+
+// Rollback restores the policy of the authenticated user to a previous revision. The rollback is recorded
+// as a new revision, and iam-authz-server is notified to reload the policies as for any other change.
+func (p *PolicyController) Rollback(c *gin.Context) {
+	log.L(c).Info("rollback policy function called.")
+
+	var r RollbackRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	username := c.GetString(middleware.UsernameKey)
+
+	revision, err := p.srv.Policies().Rollback(c, username, c.Param("name"), r.Version, username, r.Message)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, revision)
+}
@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	srvv1 "github.com/nico612/iam-demo/internal/apiserver/service/v1"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// maxSimulationRequests limits the number of sample requests of one simulation.
+const maxSimulationRequests = 100
+
+// Simulate evaluates sample requests under both the current and the proposed policies of the user,
+// without saving the proposed policies.
+func (p *PolicyController) Simulate(c *gin.Context) {
+	log.L(c).Info("simulate policy function called.")
+
+	var r srvv1.PolicySimulation
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if len(r.Requests) == 0 || len(r.Requests) > maxSimulationRequests {
+		core.WriteResponse(
+			c,
+			errors.WithCode(code.ErrValidation, "1 to %d requests are required", maxSimulationRequests),
+			nil,
+		)
+
+		return
+	}
+
+	for _, request := range r.Requests {
+		if request == nil {
+			core.WriteResponse(c, errors.WithCode(code.ErrValidation, "request can not be null"), nil)
+
+			return
+		}
+	}
+
+	result, err := p.srv.Policies().Simulate(c, c.GetString(middleware.UsernameKey), &r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, result)
+}
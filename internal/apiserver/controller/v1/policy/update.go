@@ -0,0 +1,60 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// UpdatePolicyRequest is the policy to save, with the message describing the change.
+type UpdatePolicyRequest struct {
+	PolicySource
+
+	Message string `json:"message" binding:"max=255"`
+}
+
+// Update replaces the content of the policy of the authenticated user, the change is recorded as
+// a new revision of the policy.
+func (p *PolicyController) Update(c *gin.Context) {
+	log.L(c).Info("update policy function called.")
+
+	var r UpdatePolicyRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if err := r.compile(); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	r.Name = c.Param("name")
+	if errs := r.Validate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+
+		return
+	}
+
+	username := c.GetString(middleware.UsernameKey)
+	r.Username = username
+
+	if !p.lintChange(c, &r.Policy) {
+		return
+	}
+
+	revision, err := p.srv.Policies().Update(c, &r.Policy, username, r.Message)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, revision)
+}
@@ -0,0 +1,24 @@
+package secret
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// Delete delete a secret by the secret name.
+func (s *SecretController) Delete(c *gin.Context) {
+	log.L(c).Info("delete secret function called.")
+
+	if err := s.srv.Secrets().Delete(c, c.GetString(middleware.UsernameKey), c.Param("name"),
+		metav1.DeleteOptions{Unscoped: true}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
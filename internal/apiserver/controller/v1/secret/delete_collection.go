@@ -0,0 +1,24 @@
+package secret
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// DeleteCollection delete secrets by the secret names.
+func (s *SecretController) DeleteCollection(c *gin.Context) {
+	log.L(c).Info("batch delete secret function called.")
+
+	if err := s.srv.Secrets().DeleteCollection(c, c.GetString(middleware.UsernameKey), c.QueryArray("name"),
+		metav1.DeleteOptions{Unscoped: true}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
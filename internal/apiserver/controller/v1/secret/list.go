@@ -0,0 +1,33 @@
+package secret
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// List list all the secrets of the authenticated user.
+func (s *SecretController) List(c *gin.Context) {
+	log.L(c).Info("list secret function called.")
+
+	var r metav1.ListOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	secrets, err := s.srv.Secrets().List(c, c.GetString(middleware.UsernameKey), r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, secrets)
+}
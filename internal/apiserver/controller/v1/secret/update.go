@@ -0,0 +1,51 @@
+package secret
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// Update update a key by the secret key identifier, only the expiration, description and
+// extend fields can be changed.
+func (s *SecretController) Update(c *gin.Context) {
+	log.L(c).Info("update secret function called.")
+
+	var r v1.Secret
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	secret, err := s.srv.Secrets().Get(c, c.GetString(middleware.UsernameKey), c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	secret.Expires = r.Expires
+	secret.Description = r.Description
+	secret.Extend = r.Extend
+
+	if errs := secret.Validate(); len(errs) != 0 {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
+
+		return
+	}
+
+	if err := s.srv.Secrets().Update(c, secret, metav1.UpdateOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, secret)
+}
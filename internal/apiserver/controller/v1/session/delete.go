@@ -0,0 +1,21 @@
+package session
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// Delete revokes a session of the user by its id.
+func (s *SessionController) Delete(c *gin.Context) {
+	log.L(c).Info("delete session function called.")
+
+	if err := s.srv.Sessions().Delete(c, c.Param("name"), c.Param("id")); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,21 @@
+package session
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// DeleteCollection revokes all the sessions of the user.
+func (s *SessionController) DeleteCollection(c *gin.Context) {
+	log.L(c).Info("batch delete session function called.")
+
+	if err := s.srv.Sessions().DeleteCollection(c, c.Param("name")); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
@@ -0,0 +1,22 @@
+package session
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// List returns the active sessions of the user.
+func (s *SessionController) List(c *gin.Context) {
+	log.L(c).Info("list session function called.")
+
+	sessions, err := s.srv.Sessions().List(c, c.Param("name"))
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, sessions)
+}
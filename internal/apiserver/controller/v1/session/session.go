@@ -0,0 +1,16 @@
+package session
+
+import (
+	srvv1 "github.com/nico612/iam-demo/internal/apiserver/service/v1"
+	"github.com/nico612/iam-demo/internal/apiserver/store"
+)
+
+// SessionController create a session handler used to handle request for session resource.
+type SessionController struct {
+	srv srvv1.Service
+}
+
+// NewSessionController creates a session handler.
+func NewSessionController(store store.Factory) *SessionController {
+	return &SessionController{srv: srvv1.NewService(store)}
+}
@@ -0,0 +1,22 @@
+package tenant
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// Delete deletes the tenant by its name, the tenant must have no users.
+func (t *TenantController) Delete(c *gin.Context) {
+	log.L(c).Info("delete tenant function called.")
+
+	if err := t.srv.Tenants().Delete(c, c.Param("name"), metav1.DeleteOptions{Unscoped: true}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, nil)
+}
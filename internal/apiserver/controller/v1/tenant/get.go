@@ -0,0 +1,23 @@
+package tenant
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// Get returns the tenant by its name.
+func (t *TenantController) Get(c *gin.Context) {
+	log.L(c).Info("get tenant function called.")
+
+	tenant, err := t.srv.Tenants().Get(c, c.Param("name"), metav1.GetOptions{})
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, tenant)
+}
@@ -0,0 +1,32 @@
+package tenant
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// List returns the tenants.
+func (t *TenantController) List(c *gin.Context) {
+	log.L(c).Info("list tenant function called.")
+
+	var r metav1.ListOptions
+	if err := c.ShouldBindQuery(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	tenants, err := t.srv.Tenants().List(c, r)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	core.WriteResponse(c, nil, tenants)
+}
@@ -0,0 +1,16 @@
+package tenant
+
+import (
+	srvv1 "github.com/nico612/iam-demo/internal/apiserver/service/v1"
+	"github.com/nico612/iam-demo/internal/apiserver/store"
+)
+
+// TenantController create a tenant handler used to handle request for tenant resource.
+type TenantController struct {
+	srv srvv1.Service
+}
+
+// NewTenantController creates a tenant handler.
+func NewTenantController(store store.Factory) *TenantController {
+	return &TenantController{srv: srvv1.NewService(store)}
+}
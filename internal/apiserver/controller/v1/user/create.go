@@ -7,15 +7,27 @@ import (
 	"github.com/marmotedu/component-base/pkg/core"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/errors"
+	"github.com/nico612/iam-demo/internal/apiserver/store"
 	"github.com/nico612/iam-demo/internal/pkg/code"
 	"github.com/nico612/iam-demo/pkg/log"
 	"time"
 )
 
-// Create add new user to the storage.
+// Create add new user to the storage, the user belongs to the default tenant.
 func (u *UserController) Create(c *gin.Context) {
 	log.L(c).Info("user create function called.")
 
+	u.create(c, store.DefaultTenant)
+}
+
+// CreateInTenant add new user of the tenant to the storage.
+func (u *UserController) CreateInTenant(c *gin.Context) {
+	log.L(c).Info("tenant user create function called.")
+
+	u.create(c, c.Param("name"))
+}
+
+func (u *UserController) create(c *gin.Context, tenant string) {
 	var r v1.User
 
 	if err := c.ShouldBindJSON(&r); err != nil {
@@ -24,12 +36,21 @@ func (u *UserController) Create(c *gin.Context) {
 		return
 	}
 
+	// 请求中只能使用租户内的用户名，租户由路由决定
+	if store.TenantOf(r.Name) != store.DefaultTenant {
+		core.WriteResponse(c, errors.WithCode(code.ErrValidation, "user name can not be qualified by a tenant"), nil)
+
+		return
+	}
+
 	if errs := r.Validate(); len(errs) != 0 {
 		core.WriteResponse(c, errors.WithCode(code.ErrValidation, errs.ToAggregate().Error()), nil)
 
 		return
 	}
 
+	r.Name = store.QualifyUsername(tenant, r.Name)
+
 	r.Password, _ = auth.Encrypt(r.Password)
 	r.Status = 1
 	r.LoginedAt = time.Now()
@@ -3,9 +3,12 @@ package user
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/component-base/pkg/fields"
 	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
 	"github.com/marmotedu/errors"
+	"github.com/nico612/iam-demo/internal/apiserver/store"
 	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
 	"github.com/nico612/iam-demo/pkg/log"
 )
 
@@ -19,6 +22,24 @@ func (u *UserController) List(c *gin.Context) {
 		return
 	}
 
+	// 默认租户之外的管理员只能查看本租户的用户
+	if tenant := store.TenantOf(c.GetString(middleware.UsernameKey)); tenant != store.DefaultTenant {
+		selector, err := fields.ParseSelector(r.FieldSelector)
+		if err != nil {
+			core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+			return
+		}
+
+		if value, found := selector.RequiresExactMatch("tenant"); found && value != tenant {
+			core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, "can not list users of tenant %s", value), nil)
+
+			return
+		}
+
+		r.FieldSelector = fields.AndSelectors(selector, fields.OneTermEqualSelector("tenant", tenant)).String()
+	}
+
 	users, err := u.srv.Users().List(c, r)
 	if err != nil {
 		core.WriteResponse(c, err, nil)
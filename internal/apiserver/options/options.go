@@ -5,6 +5,8 @@ import (
 
 	cliflag "github.com/marmotedu/component-base/pkg/cli/flag"
 	"github.com/marmotedu/component-base/pkg/util/idutil"
+
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
 	genericoptions "github.com/nico612/iam-demo/internal/pkg/options"
 	"github.com/nico612/iam-demo/internal/pkg/server"
 	"github.com/nico612/iam-demo/pkg/log"
@@ -12,15 +14,18 @@ import (
 
 // Options runs an iam api server.
 type Options struct {
-	GenericServerRunOptions *genericoptions.ServerRunOptions       `json:"server"   mapstructure:"server"`
-	GRPCOptions             *genericoptions.GRPCOptions            `json:"grpc"     mapstructure:"grpc"`
-	InsecureServing         *genericoptions.InsecureServingOptions `json:"insecure" mapstructure:"insecure"`
-	SecureServing           *genericoptions.SecureServingOptions   `json:"secure"   mapstructure:"secure"`
-	MySQLOptions            *genericoptions.MySQLOptions           `json:"mysql"    mapstructure:"mysql"`
-	RedisOptions            *genericoptions.RedisOptions           `json:"redis"    mapstructure:"redis"`
-	JwtOptions              *genericoptions.JwtOptions             `json:"jwt"      mapstructure:"jwt"`
-	Log                     *log.Options                           `json:"log"      mapstructure:"log"`
-	FeatureOptions          *genericoptions.FeatureOptions         `json:"feature"  mapstructure:"feature"`
+	GenericServerRunOptions *genericoptions.ServerRunOptions       `json:"server"         mapstructure:"server"`
+	GRPCOptions             *genericoptions.GRPCOptions            `json:"grpc"           mapstructure:"grpc"`
+	InsecureServing         *genericoptions.InsecureServingOptions `json:"insecure"       mapstructure:"insecure"`
+	SecureServing           *genericoptions.SecureServingOptions   `json:"secure"         mapstructure:"secure"`
+	MySQLOptions            *genericoptions.MySQLOptions           `json:"mysql"          mapstructure:"mysql"`
+	RedisOptions            *genericoptions.RedisOptions           `json:"redis"          mapstructure:"redis"`
+	JwtOptions              *genericoptions.JwtOptions             `json:"jwt"            mapstructure:"jwt"`
+	Log                     *log.Options                           `json:"log"            mapstructure:"log"`
+	FeatureOptions          *genericoptions.FeatureOptions         `json:"feature"        mapstructure:"feature"`
+	Authentication          *genericoptions.AuthenticationOptions  `json:"authentication" mapstructure:"authentication"`
+	NotificationOptions     *genericoptions.NotificationOptions    `json:"notification"   mapstructure:"notification"`
+	PolicyLintOptions       *authorization.LintOptions             `json:"policy-lint"    mapstructure:"policy-lint"`
 }
 
 // NewOptions creates a new Options object with default parameters.
@@ -35,6 +40,9 @@ func NewOptions() *Options {
 		JwtOptions:              genericoptions.NewJwtOptions(),
 		Log:                     log.NewOptions(),
 		FeatureOptions:          genericoptions.NewFeatureOptions(),
+		Authentication:          genericoptions.NewAuthenticationOptions("jwt", "apikey", "basic", "mtls"),
+		NotificationOptions:     genericoptions.NewNotificationOptions(),
+		PolicyLintOptions:       authorization.NewLintOptions(),
 	}
 
 	return &o
@@ -55,9 +63,12 @@ func (o *Options) Validate() []error {
 	errs = append(errs, o.SecureServing.Validate()...)
 	errs = append(errs, o.MySQLOptions.Validate()...)
 	errs = append(errs, o.RedisOptions.Validate()...)
+	errs = append(errs, o.NotificationOptions.Validate()...)
 	errs = append(errs, o.JwtOptions.Validate()...)
 	errs = append(errs, o.Log.Validate()...)
 	errs = append(errs, o.FeatureOptions.Validate()...)
+	errs = append(errs, o.Authentication.Validate()...)
+	errs = append(errs, o.PolicyLintOptions.Validate()...)
 
 	return errs
 }
@@ -69,9 +80,12 @@ func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
 	o.GRPCOptions.AddFlags(fss.FlagSet("grpc"))
 	o.MySQLOptions.AddFlags(fss.FlagSet("mysql"))
 	o.RedisOptions.AddFlags(fss.FlagSet("redis"))
+	o.NotificationOptions.AddFlags(fss.FlagSet("notification"))
 	o.FeatureOptions.AddFlags(fss.FlagSet("features"))
 	o.InsecureServing.AddFlags(fss.FlagSet("insecure serving"))
 	o.SecureServing.AddFlags(fss.FlagSet("secure serving"))
+	o.Authentication.AddFlags(fss.FlagSet("authentication"))
+	o.PolicyLintOptions.AddFlags(fss.FlagSet("policy lint"))
 	o.Log.AddFlags(fss.FlagSet("logs"))
 
 	return fss
@@ -4,37 +4,54 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/marmotedu/component-base/pkg/core"
 	"github.com/marmotedu/errors"
+	"github.com/nico612/iam-demo/internal/apiserver/controller/v1/apikey"
+	"github.com/nico612/iam-demo/internal/apiserver/controller/v1/policy"
+	"github.com/nico612/iam-demo/internal/apiserver/controller/v1/secret"
+	"github.com/nico612/iam-demo/internal/apiserver/controller/v1/session"
+	"github.com/nico612/iam-demo/internal/apiserver/controller/v1/tenant"
 	"github.com/nico612/iam-demo/internal/apiserver/controller/v1/user"
 	"github.com/nico612/iam-demo/internal/apiserver/store/mysql"
 	"github.com/nico612/iam-demo/internal/pkg/code"
 	"github.com/nico612/iam-demo/internal/pkg/middleware"
 	"github.com/nico612/iam-demo/internal/pkg/middleware/auth"
+	genericoptions "github.com/nico612/iam-demo/internal/pkg/options"
 )
 
-func initRouter(g *gin.Engine) {
+func initRouter(
+	g *gin.Engine,
+	authentication *genericoptions.AuthenticationOptions,
+	clientCert *genericoptions.ClientCertAuthenticationOptions,
+) {
 	installMiddleware(g)
-	installController(g)
+	installController(g, authentication, clientCert)
 }
 
 func installMiddleware(g *gin.Engine) {
 
 }
 
-func installController(g *gin.Engine) *gin.Engine {
+func installController(
+	g *gin.Engine,
+	authentication *genericoptions.AuthenticationOptions,
+	clientCert *genericoptions.ClientCertAuthenticationOptions,
+) *gin.Engine {
 
 	// Middlewares.
 	jwtStrategy, _ := newJWTAuth().(auth.JWTStrategy)
 	g.POST("/login", jwtStrategy.LoginHandler)
-	g.POST("/logout", jwtStrategy.LogoutHandler)
-	g.POST("refresh", jwtStrategy.RefreshHandler)
+	g.POST("/logout", logoutHandler(jwtStrategy))
+	g.POST("/refresh", refreshHandler(jwtStrategy))
 
-	auto := newJWTAuth()
+	auto := newAuthChain(authentication.Strategies, clientCert)
 
 	// 404
 	g.NoRoute(auto.AuthFunc(), func(c *gin.Context) {
 		core.WriteResponse(c, errors.WithCode(code.ErrPageNotFound, "Page not found."), nil)
 	})
 
+	// 租户用户的用户名形如 <tenant>/<name>，在路径参数中编码为 <tenant>%2F<name>
+	g.UseRawPath = true
+
 	storeIns, _ := mysql.GetMySQLFactoryOr(nil)
 	v1 := g.Group("/v1")
 	{
@@ -50,10 +67,66 @@ func installController(g *gin.Engine) *gin.Engine {
 			userv1.PUT(":name", userController.Update)
 			userv1.GET("", userController.List)
 			userv1.GET(":name", userController.Get) // admin api
+
+			// api key RESTful resource
+			apikeyController := apikey.NewAPIKeyController(storeIns)
+			userv1.POST(":name/apikeys", apikeyController.Create)
+			userv1.GET(":name/apikeys", apikeyController.List)
+			userv1.DELETE(":name/apikeys/:id", apikeyController.Delete)
+
+			// session RESTful resource
+			sessionController := session.NewSessionController(storeIns)
+			userv1.GET(":name/sessions", sessionController.List)
+			userv1.DELETE(":name/sessions", sessionController.DeleteCollection)
+			userv1.DELETE(":name/sessions/:id", sessionController.Delete)
 		}
 
 		v1.Use(auto.AuthFunc())
 
+		// policy RESTful resource, policies belong to the authenticated user
+		policyv1 := v1.Group("/policies")
+		{
+			policyController := policy.NewPolicyController(storeIns)
+			policyv1.POST("", policyController.Create)
+			policyv1.POST("simulate", policyController.Simulate)
+			policyv1.POST("lint", policyController.Lint)
+			policyv1.PUT(":name", policyController.Update)
+			policyv1.DELETE("", policyController.DeleteCollection)
+			policyv1.DELETE(":name", policyController.Delete)
+
+			// revisions of the policy
+			policyv1.GET(":name/revisions", policyController.ListRevisions)
+			policyv1.GET(":name/revisions/:version", policyController.GetRevision)
+			policyv1.GET(":name/diff", policyController.DiffRevisions)
+			policyv1.POST(":name/rollback", policyController.Rollback)
+		}
+
+		// secret RESTful resource, secrets belong to the authenticated user
+		secretv1 := v1.Group("/secrets")
+		{
+			secretController := secret.NewSecretController(storeIns)
+			secretv1.POST("", secretController.Create)
+			secretv1.DELETE(":name", secretController.Delete)
+			secretv1.DELETE("", secretController.DeleteCollection)
+			secretv1.PUT(":name", secretController.Update)
+			secretv1.GET("", secretController.List)
+			secretv1.GET(":name", secretController.Get)
+		}
+
+		// tenant RESTful resource, tenants are managed by the administrators
+		tenantv1 := v1.Group("/tenants", middleware.Validation())
+		{
+			tenantController := tenant.NewTenantController(storeIns)
+			tenantv1.POST("", tenantController.Create)
+			tenantv1.GET("", tenantController.List)
+			tenantv1.GET(":name", tenantController.Get)
+			tenantv1.DELETE(":name", tenantController.Delete)
+
+			// users of the tenant
+			userController := user.NewUserController(storeIns)
+			tenantv1.POST(":name/users", userController.CreateInTenant)
+		}
+
 	}
 
 	return g
@@ -2,8 +2,10 @@ package apiserver
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	deltapb "github.com/nico612/iam-demo/api/proto/apiserver/v1"
 	"github.com/nico612/iam-demo/internal/apiserver/config"
 	"github.com/nico612/iam-demo/internal/apiserver/store"
 	"github.com/nico612/iam-demo/internal/apiserver/store/mysql"
@@ -17,6 +19,7 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	cachev1 "github.com/nico612/iam-demo/internal/apiserver/controller/v1/cache"
+	srvv1 "github.com/nico612/iam-demo/internal/apiserver/service/v1"
 	genericapiserver "github.com/nico612/iam-demo/internal/pkg/server"
 )
 
@@ -25,6 +28,9 @@ import (
 type apiServer struct {
 	gs               *shutdown.GracefulShutdown // 优雅关闭服务
 	redisOptions     *genericoptions.RedisOptions
+	notification     *genericoptions.NotificationOptions
+	authentication   *genericoptions.AuthenticationOptions
+	clientCert       *genericoptions.ClientCertAuthenticationOptions
 	gRPCAPIServer    *grpcAPIServer
 	genericapiserver *genericapiserver.GenericAPIServer
 }
@@ -38,6 +44,7 @@ type ExtraConfig struct {
 	Addr         string
 	MaxMsgSize   int
 	ServerCert   genericoptions.GeneratableKeyCert
+	ClientCert   genericoptions.ClientCertAuthenticationOptions
 	mysqlOptions *genericoptions.MySQLOptions
 	// etcdOptions      *genericoptions.EtcdOptions
 }
@@ -82,9 +89,17 @@ func createAPIServer(cfg *config.Config) (*apiServer, error) {
 		return nil, err
 	}
 
+	// 策略检查的配置在启动时构建，配置错误时启动失败
+	if err := srvv1.SetPolicyLintOptions(cfg.PolicyLintOptions); err != nil {
+		return nil, err
+	}
+
 	server := &apiServer{
 		gs:               gs,
 		redisOptions:     cfg.RedisOptions,
+		notification:     cfg.NotificationOptions,
+		authentication:   cfg.Authentication,
+		clientCert:       &cfg.SecureServing.ClientCert,
 		genericapiserver: genericServer, // HTTP HTTPS 服务
 		gRPCAPIServer:    extraServer,   // gRPC 服务
 	}
@@ -96,7 +111,10 @@ func createAPIServer(cfg *config.Config) (*apiServer, error) {
 // PrepareRun 执行apiServer初始化
 func (s *apiServer) PrepareRun() preparedAPIServer {
 
+	initRouter(s.genericapiserver.Engine, s.authentication, s.clientCert)
+
 	s.initRedisStore()
+	srvv1.SetNotificationOptions(s.notification)
 
 	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
 		mysqlStore, _ := mysql.GetMySQLFactoryOr(nil)
@@ -127,10 +145,22 @@ func (s preparedAPIServer) Run() error {
 
 // New create a grpcAPIServer instance.
 func (c *completedExtraConfig) New() (*grpcAPIServer, error) {
-	creds, err := credentials.NewServerTLSFromFile(c.ServerCert.CertKey.CertFile, c.ServerCert.CertKey.KeyFile)
+	cert, err := tls.LoadX509KeyPair(c.ServerCert.CertKey.CertFile, c.ServerCert.CertKey.KeyFile)
 	if err != nil {
 		log.Fatalf("Failed to generate credentials %s", err.Error())
 	}
+
+	// 配置了 client-ca-file 时，gRPC 服务同样校验客户端证书
+	tlsConfig, err := genericapiserver.NewClientAuthTLSConfig(c.ClientCert.ClientCA, c.ClientCert.Required)
+	if err != nil {
+		log.Fatalf("Failed to load client ca: %s", err.Error())
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig.Certificates = []tls.Certificate{cert}
+
+	creds := credentials.NewTLS(tlsConfig)
 	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(c.MaxMsgSize), grpc.Creds(creds)}
 	grpcServer := grpc.NewServer(opts...)
 
@@ -144,6 +174,7 @@ func (c *completedExtraConfig) New() (*grpcAPIServer, error) {
 	}
 
 	pb.RegisterCacheServer(grpcServer, cacheIns)
+	deltapb.RegisterCacheDeltaServer(grpcServer, cachev1.NewCacheDelta(cacheIns))
 
 	reflection.Register(grpcServer)
 
@@ -156,6 +187,7 @@ func buildExtraConfig(cfg *config.Config) (*ExtraConfig, error) {
 		Addr:         fmt.Sprintf("%s:%d", cfg.GRPCOptions.BindAddress, cfg.GRPCOptions.BindPort),
 		MaxMsgSize:   cfg.GRPCOptions.MaxMsgSize,
 		ServerCert:   cfg.SecureServing.ServerCert,
+		ClientCert:   cfg.SecureServing.ClientCert,
 		mysqlOptions: cfg.MySQLOptions,
 	}, nil
 }
@@ -0,0 +1,208 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/apiserver/store"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/pkg/log"
+	"github.com/nico612/iam-demo/pkg/storage"
+)
+
+// APIKey describes an opaque api key minted for a user.
+// Only the hash of the key is persisted, the plaintext Token is returned once when the key is created.
+type APIKey struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Description string    `json:"description,omitempty"`
+	Expires     int64     `json:"expires"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Token       string    `json:"token,omitempty"`
+}
+
+// APIKeyList is the whole list of api keys owned by a user.
+type APIKeyList struct {
+	TotalCount int64     `json:"totalCount"`
+	Items      []*APIKey `json:"items"`
+}
+
+type APIKeySrv interface {
+	Create(ctx context.Context, key *APIKey) error
+	Delete(ctx context.Context, username, id string) error
+	DeleteCollection(ctx context.Context, username string) error
+	List(ctx context.Context, username string) (*APIKeyList, error)
+	Get(ctx context.Context, token string) (*APIKey, error)
+}
+
+// api key 存储在 redis 中：
+// apikey-<hash(token)> 保存 api key 的元信息，key 名经过 hash，明文 token 不落盘
+// apikey-index-<username> 是一个集合，成员为 <id>:<hash(token)>，用于按用户列举和按 ID 吊销.
+var (
+	apiKeyStore = &storage.RedisCluster{KeyPrefix: "apikey-", HashKeys: true}
+	apiKeyIndex = &storage.RedisCluster{KeyPrefix: "apikey-index-"}
+)
+
+type apiKeyService struct {
+	store store.Factory
+}
+
+var _ APIKeySrv = (*apiKeyService)(nil)
+
+func newAPIKeys(srv *service) *apiKeyService {
+	return &apiKeyService{store: srv.store}
+}
+
+// Create mints a new api key for key.Username and fills key.Token with the plaintext key.
+func (a *apiKeyService) Create(ctx context.Context, key *APIKey) error {
+	if _, err := a.store.Users().Get(ctx, key.Username, metav1.GetOptions{}); err != nil {
+		return err
+	}
+
+	token, err := storage.GenerateToken("", "", storage.HashSha256)
+	if err != nil {
+		return errors.WithCode(code.ErrUnknown, err.Error())
+	}
+
+	key.ID = idutil.GetUUID36("apikey-")
+	key.CreatedAt = time.Now()
+	key.Token = ""
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return errors.WithCode(code.ErrEncodingJSON, err.Error())
+	}
+
+	var ttl time.Duration
+	if key.Expires > 0 {
+		ttl = time.Until(time.Unix(key.Expires, 0))
+	}
+
+	if err := apiKeyStore.SetKey(token, string(data), ttl); err != nil {
+		log.L(ctx).Errorf("save api key failed: %s", err.Error())
+
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	// 不在索引中的 api key 无法列举和吊销，写索引失败时删除 api key
+	if err := apiKeyIndex.AddToSet(key.Username, key.ID+":"+storage.HashKey(token)); err != nil {
+		log.L(ctx).Errorf("index api key failed: %s", err.Error())
+		apiKeyStore.DeleteKey(token)
+
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	key.Token = token
+
+	return nil
+}
+
+// Delete revokes the api key identified by id.
+func (a *apiKeyService) Delete(ctx context.Context, username, id string) error {
+	members, err := apiKeyIndex.GetSet(username)
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	for _, member := range members {
+		keyID, hash := splitAPIKeyMember(member)
+		if keyID != id {
+			continue
+		}
+
+		apiKeyStore.DeleteRawKey(apiKeyStore.KeyPrefix + hash)
+		apiKeyIndex.RemoveFromSet(username, member)
+
+		return nil
+	}
+
+	return errors.WithCode(code.ErrAPIKeyNotFound, "api key %s not found", id)
+}
+
+// DeleteCollection revokes all the api keys owned by username.
+func (a *apiKeyService) DeleteCollection(ctx context.Context, username string) error {
+	members, err := apiKeyIndex.GetSet(username)
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	for _, member := range members {
+		_, hash := splitAPIKeyMember(member)
+		apiKeyStore.DeleteRawKey(apiKeyStore.KeyPrefix + hash)
+	}
+
+	apiKeyIndex.DeleteKey(username)
+
+	return nil
+}
+
+// List returns all the unexpired api keys owned by username.
+func (a *apiKeyService) List(ctx context.Context, username string) (*APIKeyList, error) {
+	members, err := apiKeyIndex.GetSet(username)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	items := make([]*APIKey, 0, len(members))
+	for _, member := range members {
+		_, hash := splitAPIKeyMember(member)
+
+		value, err := apiKeyStore.GetRawKey(apiKeyStore.KeyPrefix + hash)
+		if err != nil {
+			// the key has expired, drop the dangling index entry.
+			apiKeyIndex.RemoveFromSet(username, member)
+
+			continue
+		}
+
+		var key APIKey
+		if err := json.Unmarshal([]byte(value), &key); err != nil {
+			log.L(ctx).Warnf("decode api key failed: %s", err.Error())
+
+			continue
+		}
+
+		items = append(items, &key)
+	}
+
+	return &APIKeyList{TotalCount: int64(len(items)), Items: items}, nil
+}
+
+// Get returns the api key matching the plaintext token. The keys of deleted users are reported as
+// ErrAPIKeyNotFound.
+func (a *apiKeyService) Get(ctx context.Context, token string) (*APIKey, error) {
+	value, err := apiKeyStore.GetKey(token)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrAPIKeyNotFound, err.Error())
+	}
+
+	var key APIKey
+	if err := json.Unmarshal([]byte(value), &key); err != nil {
+		return nil, errors.WithCode(code.ErrDecodingJSON, err.Error())
+	}
+
+	if _, err := a.store.Users().Get(ctx, key.Username, metav1.GetOptions{}); err != nil {
+		if errors.IsCode(err, code.ErrUserNotFound) {
+			return nil, errors.WithCode(code.ErrAPIKeyNotFound, "owner of api key %s not found", key.ID)
+		}
+
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func splitAPIKeyMember(member string) (id, hash string) {
+	parts := strings.SplitN(member, ":", 2)
+	if len(parts) != 2 {
+		return member, ""
+	}
+
+	return parts[0], parts[1]
+}
@@ -0,0 +1,38 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/nico612/iam-demo/internal/pkg/notification"
+	genericoptions "github.com/nico612/iam-demo/internal/pkg/options"
+	"github.com/nico612/iam-demo/pkg/log"
+	"github.com/nico612/iam-demo/pkg/storage"
+)
+
+var notifier *notification.RedisNotifier
+
+// SetNotificationOptions sets the options of the cluster notifications published by the services,
+// notifications are signed with the first key. Notifications are dropped until the options are set.
+func SetNotificationOptions(opts *genericoptions.NotificationOptions) {
+	if opts == nil || len(opts.Keys) == 0 {
+		notifier = nil
+
+		return
+	}
+
+	notifier = notification.NewRedisNotifier(&storage.RedisCluster{}, notification.RedisPubSubChannel, opts.Keys[0])
+}
+
+// notify publishes a cluster notification, so that iam-authz-server refetches the changed entries.
+// A lost notification only delays the change until the next full reload.
+func notify(ctx context.Context, n notification.Notification) {
+	if notifier == nil {
+		log.L(ctx).Warnf("no notification key configured, drop notification %s", n.Command)
+
+		return
+	}
+
+	if !notifier.Notify(n) {
+		log.L(ctx).Warnf("failed to publish notification %s", n.Command)
+	}
+}
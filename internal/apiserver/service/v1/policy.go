@@ -0,0 +1,223 @@
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+
+	"github.com/nico612/iam-demo/internal/apiserver/store"
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/notification"
+)
+
+// PolicySimulation describes a proposed change of the policies of a user and the requests to evaluate.
+// The proposed policies are given either as a whole set by Policies, or as a diff against the current
+// policies by Upsert and Delete. Policies are identified by their id, which is the policy name.
+type PolicySimulation struct {
+	Policies []*ladon.DefaultPolicy `json:"policies,omitempty"`
+	Upsert   []*ladon.DefaultPolicy `json:"upsert,omitempty"`
+	Delete   []string               `json:"delete,omitempty"`
+	Requests []*ladon.Request       `json:"requests"`
+}
+
+// PolicySimulationResult is the outcome of every simulated request, in the order of the requests.
+type PolicySimulationResult struct {
+	// ChangedCount is the number of requests whose outcome changes under the proposed policies.
+	ChangedCount int                      `json:"changedCount"`
+	Items        []*PolicySimulationEntry `json:"items"`
+}
+
+// PolicySimulationEntry compares the outcome of a request under the current and the proposed policies.
+type PolicySimulationEntry struct {
+	Request  *ladon.Request          `json:"request"`
+	Current  *authorization.Response `json:"current"`
+	Proposed *authorization.Response `json:"proposed"`
+	Changed  bool                    `json:"changed"`
+}
+
+type PolicySrv interface {
+	Create(ctx context.Context, policy *v1.Policy, opts metav1.CreateOptions) error
+	Update(ctx context.Context, policy *v1.Policy, author, message string) (*store.PolicyRevision, error)
+	Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, username string, names []string, opts metav1.DeleteOptions) error
+	ListRevisions(
+		ctx context.Context,
+		username, name string,
+		opts metav1.ListOptions,
+	) (*store.PolicyRevisionList, error)
+	GetRevision(ctx context.Context, username, name string, version int) (*store.PolicyRevision, error)
+	DiffRevisions(ctx context.Context, username, name string, from, to int) (*PolicyRevisionDiff, error)
+	Rollback(
+		ctx context.Context,
+		username, name string,
+		version int,
+		author, message string,
+	) (*store.PolicyRevision, error)
+	Simulate(ctx context.Context, username string, simulation *PolicySimulation) (*PolicySimulationResult, error)
+	Lint(ctx context.Context, username string, policies []*authorization.LintPolicy) (*authorization.LintResult, error)
+	LintChange(ctx context.Context, policy *v1.Policy) (*authorization.LintResult, error)
+}
+
+type policyService struct {
+	store store.Factory
+}
+
+var _ PolicySrv = (*policyService)(nil)
+
+func newPolicies(srv *service) *policyService {
+	return &policyService{store: srv.store}
+}
+
+// Create saves the policy with a revision after checking its conditions, and notifies
+// iam-authz-server to reload the policies of the user.
+func (p *policyService) Create(ctx context.Context, policy *v1.Policy, opts metav1.CreateOptions) error {
+	if err := authorization.ValidateConditions(policy.Policy.Conditions); err != nil {
+		return errors.WithCode(code.ErrValidation, err.Error())
+	}
+
+	_, err := p.commit(ctx, policy, policy.Username, "Create policy")
+
+	return err
+}
+
+// Delete deletes the policy of the user, and notifies iam-authz-server to reload the policies of the user.
+func (p *policyService) Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error {
+	if err := p.store.Policies().Delete(ctx, username, name, opts); err != nil {
+		return err
+	}
+
+	notify(ctx, notification.NewPolicyChangedNotification(username))
+
+	return nil
+}
+
+// DeleteCollection batch deletes the policies of the user with a single notification.
+func (p *policyService) DeleteCollection(
+	ctx context.Context,
+	username string,
+	names []string,
+	opts metav1.DeleteOptions,
+) error {
+	if err := p.store.Policies().DeleteCollection(ctx, username, names, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	notify(ctx, notification.NewPolicyChangedNotification(username))
+
+	return nil
+}
+
+// Simulate evaluates the requests under both the current and the proposed policies of the user.
+// The policies are evaluated by the same authorizer as iam-authz-server, nothing is saved or audited.
+func (p *policyService) Simulate(
+	ctx context.Context,
+	username string,
+	simulation *PolicySimulation,
+) (*PolicySimulationResult, error) {
+	if len(simulation.Policies) > 0 && (len(simulation.Upsert) > 0 || len(simulation.Delete) > 0) {
+		return nil, errors.WithCode(code.ErrValidation, "policies can not be used together with upsert or delete")
+	}
+
+	for _, policy := range append(simulation.Policies, simulation.Upsert...) {
+		if err := authorization.ValidateConditions(policy.Conditions); err != nil {
+			return nil, errors.WithCode(code.ErrValidation, "policy %s: %s", policy.ID, err.Error())
+		}
+	}
+
+	// 用户属性条件需要用户的 Extend 数据，和 iam-authz-server 一样注入到请求上下文中
+	var attributes map[string]interface{}
+	if user, err := p.store.Users().Get(ctx, username, metav1.GetOptions{}); err == nil && len(user.Extend) > 0 {
+		attributes = user.Extend
+	}
+
+	list, err := p.store.Policies().List(ctx, username, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	current := make([]*ladon.DefaultPolicy, 0, len(list.Items))
+	for _, policy := range list.Items {
+		current = append(current, &policy.Policy.DefaultPolicy)
+	}
+
+	proposed := simulation.Policies
+	if len(proposed) == 0 {
+		proposed = applyPolicyDiff(current, simulation.Upsert, simulation.Delete)
+	}
+
+	currentAuthorizer := authorization.NewAuthorizer(staticPolicies(current))
+	proposedAuthorizer := authorization.NewAuthorizer(staticPolicies(proposed))
+
+	result := &PolicySimulationResult{Items: make([]*PolicySimulationEntry, 0, len(simulation.Requests))}
+	for _, request := range simulation.Requests {
+		if request.Context == nil {
+			request.Context = ladon.Context{}
+		}
+		request.Context["username"] = username
+		delete(request.Context, authorization.ContextKeyUserAttributes)
+		if attributes != nil {
+			request.Context[authorization.ContextKeyUserAttributes] = attributes
+		}
+
+		entry := &PolicySimulationEntry{
+			Request:  request,
+			Current:  currentAuthorizer.Authorize(request),
+			Proposed: proposedAuthorizer.Authorize(request),
+		}
+		entry.Changed = entry.Current.Allowed != entry.Proposed.Allowed
+		if entry.Changed {
+			result.ChangedCount++
+		}
+
+		result.Items = append(result.Items, entry)
+	}
+
+	return result, nil
+}
+
+// applyPolicyDiff returns the policies after replacing or adding the upserted policies and removing the deleted ones.
+func applyPolicyDiff(current, upsert []*ladon.DefaultPolicy, deleted []string) []*ladon.DefaultPolicy {
+	removed := make(map[string]bool, len(deleted)+len(upsert))
+	for _, id := range deleted {
+		removed[id] = true
+	}
+
+	for _, policy := range upsert {
+		removed[policy.ID] = true
+	}
+
+	policies := make([]*ladon.DefaultPolicy, 0, len(current)+len(upsert))
+	for _, policy := range current {
+		if !removed[policy.ID] {
+			policies = append(policies, policy)
+		}
+	}
+
+	return append(policies, upsert...)
+}
+
+// staticPolicies implements authorization.AuthorizationInterface with a fixed policy set,
+// the decisions it makes are not recorded.
+type staticPolicies []*ladon.DefaultPolicy
+
+var _ authorization.AuthorizationInterface = staticPolicies(nil)
+
+func (s staticPolicies) Create(*ladon.DefaultPolicy) error { return nil }
+
+func (s staticPolicies) Update(*ladon.DefaultPolicy) error { return nil }
+
+func (s staticPolicies) Delete(string) error { return nil }
+
+func (s staticPolicies) DeleteCollection([]string) error { return nil }
+
+func (s staticPolicies) Get(string) (*ladon.DefaultPolicy, error) { return &ladon.DefaultPolicy{}, nil }
+
+func (s staticPolicies) List(string) ([]*ladon.DefaultPolicy, error) { return s, nil }
+
+func (s staticPolicies) LogRejectedAccessRequest(*ladon.Request, ladon.Policies, ladon.Policies) {}
+
+func (s staticPolicies) LogGrantedAccessRequest(*ladon.Request, ladon.Policies, ladon.Policies) {}
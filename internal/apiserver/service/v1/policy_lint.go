@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+)
+
+// Lint analyzes the policies, or the saved policies of the user when no policies are given.
+func (p *policyService) Lint(
+	ctx context.Context,
+	username string,
+	policies []*authorization.LintPolicy,
+) (*authorization.LintResult, error) {
+	if len(policies) == 0 {
+		var err error
+		if policies, err = p.savedLintPolicies(ctx, username); err != nil {
+			return nil, err
+		}
+	}
+
+	return linter.Lint(policies), nil
+}
+
+// LintChange analyzes the policy set of the user as if the policy is saved, and returns the findings
+// involving the policy. The findings of the other saved policies do not block the change.
+func (p *policyService) LintChange(ctx context.Context, policy *v1.Policy) (*authorization.LintResult, error) {
+	policies, err := p.savedLintPolicies(ctx, policy.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := authorization.NewLintPolicy(&policy.Policy.DefaultPolicy)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrEncodingJSON, err.Error())
+	}
+	// 策略保存时 ID 会被设置为策略名
+	changed.ID = policy.Name
+
+	replaced := false
+	for i, saved := range policies {
+		if saved.ID == changed.ID {
+			policies[i] = changed
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		policies = append(policies, changed)
+	}
+
+	return linter.Lint(policies).Involving(changed.ID), nil
+}
+
+// savedLintPolicies returns the saved policies of the user to lint.
+func (p *policyService) savedLintPolicies(ctx context.Context, username string) ([]*authorization.LintPolicy, error) {
+	list, err := p.store.Policies().List(ctx, username, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	policies := make([]*authorization.LintPolicy, 0, len(list.Items))
+	// 按创建顺序检查，重复的策略报告在后创建的策略上
+	for i := len(list.Items) - 1; i >= 0; i-- {
+		policy, err := authorization.NewLintPolicy(&list.Items[i].Policy.DefaultPolicy)
+		if err != nil {
+			return nil, errors.WithCode(code.ErrEncodingJSON, err.Error())
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// linter is the policy linter with the configured severities, the default severities are used
+// until the lint options are set.
+var linter, _ = authorization.NewLinter(nil)
+
+// SetPolicyLintOptions builds the policy linter from the lint options, it returns an error when
+// the severities are invalid.
+func SetPolicyLintOptions(opts *authorization.LintOptions) error {
+	if opts == nil {
+		return nil
+	}
+
+	l, err := authorization.NewLinter(opts.Severity)
+	if err != nil {
+		return err
+	}
+
+	linter = l
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/apiserver/store"
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/notification"
+)
+
+// PolicyRevisionDiff lists the changed fields of a policy between two revisions.
+type PolicyRevisionDiff struct {
+	Name    string               `json:"name"`
+	From    int                  `json:"from"`
+	To      int                  `json:"to"`
+	Changes []*PolicyFieldChange `json:"changes"`
+}
+
+// PolicyFieldChange is the change of one field of a policy. The changes of the list fields are given by
+// Added and Removed, the conditions are compared one by one as the `conditions.<name>` fields.
+type PolicyFieldChange struct {
+	Field   string      `json:"field"`
+	From    interface{} `json:"from,omitempty"`
+	To      interface{} `json:"to,omitempty"`
+	Added   []string    `json:"added,omitempty"`
+	Removed []string    `json:"removed,omitempty"`
+}
+
+// policyContent is the content of a policy revision, conditions and meta are kept as raw json to be compared.
+type policyContent struct {
+	Description string                     `json:"description"`
+	Subjects    []string                   `json:"subjects"`
+	Effect      string                     `json:"effect"`
+	Resources   []string                   `json:"resources"`
+	Actions     []string                   `json:"actions"`
+	Conditions  map[string]json.RawMessage `json:"conditions"`
+	Meta        json.RawMessage            `json:"meta"`
+}
+
+// Update replaces the content of the policy and appends a revision, then notifies iam-authz-server to reload
+// the policies of the user.
+func (p *policyService) Update(
+	ctx context.Context,
+	policy *v1.Policy,
+	author, message string,
+) (*store.PolicyRevision, error) {
+	if err := authorization.ValidateConditions(policy.Policy.Conditions); err != nil {
+		return nil, errors.WithCode(code.ErrValidation, err.Error())
+	}
+
+	current, err := p.store.Policies().Get(ctx, policy.Username, policy.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	current.Policy = policy.Policy
+	if policy.Extend != nil {
+		current.Extend = policy.Extend
+	}
+
+	if message == "" {
+		message = "Update policy"
+	}
+
+	return p.commit(ctx, current, author, message)
+}
+
+// ListRevisions returns the revisions of the policy, the latest first.
+func (p *policyService) ListRevisions(
+	ctx context.Context,
+	username, name string,
+	opts metav1.ListOptions,
+) (*store.PolicyRevisionList, error) {
+	revisions, err := p.store.PolicyRevisions().List(ctx, username, name, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return revisions, nil
+}
+
+// GetRevision returns the revision of the policy by the version.
+func (p *policyService) GetRevision(
+	ctx context.Context,
+	username, name string,
+	version int,
+) (*store.PolicyRevision, error) {
+	return p.store.PolicyRevisions().Get(ctx, username, name, version)
+}
+
+// DiffRevisions compares the policy of revision `from` with the one of revision `to`.
+func (p *policyService) DiffRevisions(
+	ctx context.Context,
+	username, name string,
+	from, to int,
+) (*PolicyRevisionDiff, error) {
+	fromRevision, err := p.store.PolicyRevisions().Get(ctx, username, name, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toRevision, err := p.store.PolicyRevisions().Get(ctx, username, name, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var fromContent, toContent policyContent
+	if err := json.Unmarshal([]byte(fromRevision.PolicyShadow), &fromContent); err != nil {
+		return nil, errors.WithCode(code.ErrDecodingJSON, err.Error())
+	}
+
+	if err := json.Unmarshal([]byte(toRevision.PolicyShadow), &toContent); err != nil {
+		return nil, errors.WithCode(code.ErrDecodingJSON, err.Error())
+	}
+
+	return &PolicyRevisionDiff{
+		Name:    name,
+		From:    from,
+		To:      to,
+		Changes: diffPolicyContent(&fromContent, &toContent),
+	}, nil
+}
+
+// Rollback restores the policy to the content of the revision, the rollback is recorded as a new revision.
+func (p *policyService) Rollback(
+	ctx context.Context,
+	username, name string,
+	version int,
+	author, message string,
+) (*store.PolicyRevision, error) {
+	revision, err := p.store.PolicyRevisions().Get(ctx, username, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := p.store.Policies().Get(ctx, username, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	policy.Policy = v1.AuthzPolicy{}
+	if err := json.Unmarshal([]byte(revision.PolicyShadow), &policy.Policy); err != nil {
+		return nil, errors.WithCode(code.ErrDecodingJSON, err.Error())
+	}
+
+	if message == "" {
+		message = fmt.Sprintf("Rollback to version %d", version)
+	}
+
+	return p.commit(ctx, policy, author, message)
+}
+
+// commit saves the policy with a new revision and notifies iam-authz-server to reload the policies of the user.
+func (p *policyService) commit(
+	ctx context.Context,
+	policy *v1.Policy,
+	author, message string,
+) (*store.PolicyRevision, error) {
+	revision := &store.PolicyRevision{Author: author, Message: message}
+	if err := p.store.PolicyRevisions().Commit(ctx, policy, revision); err != nil {
+		if match, _ := regexp.MatchString("Duplicate entry '.*' for key", err.Error()); match {
+			return nil, errors.WithCode(code.ErrPolicyAlreadyExist, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	notify(ctx, notification.NewPolicyChangedNotification(policy.Username))
+
+	return revision, nil
+}
+
+// diffPolicyContent returns the changed fields from the policy content `from` to `to`.
+func diffPolicyContent(from, to *policyContent) []*PolicyFieldChange {
+	changes := make([]*PolicyFieldChange, 0)
+
+	if from.Description != to.Description {
+		changes = append(changes, &PolicyFieldChange{Field: "description", From: from.Description, To: to.Description})
+	}
+
+	if from.Effect != to.Effect {
+		changes = append(changes, &PolicyFieldChange{Field: "effect", From: from.Effect, To: to.Effect})
+	}
+
+	for _, list := range []struct {
+		field    string
+		from, to []string
+	}{
+		{"subjects", from.Subjects, to.Subjects},
+		{"resources", from.Resources, to.Resources},
+		{"actions", from.Actions, to.Actions},
+	} {
+		added, removed := diffStrings(list.from, list.to)
+		if len(added) > 0 || len(removed) > 0 {
+			changes = append(changes, &PolicyFieldChange{Field: list.field, Added: added, Removed: removed})
+		}
+	}
+
+	names := make([]string, 0, len(from.Conditions)+len(to.Conditions))
+	for name := range from.Conditions {
+		names = append(names, name)
+	}
+
+	for name := range to.Conditions {
+		if _, ok := from.Conditions[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		fromCondition, toCondition := from.Conditions[name], to.Conditions[name]
+		if !bytes.Equal(fromCondition, toCondition) {
+			change := &PolicyFieldChange{Field: "conditions." + name}
+			// 缺少的一侧不输出，避免 nil 的 RawMessage 序列化为 null
+			if fromCondition != nil {
+				change.From = fromCondition
+			}
+			if toCondition != nil {
+				change.To = toCondition
+			}
+
+			changes = append(changes, change)
+		}
+	}
+
+	if !bytes.Equal(from.Meta, to.Meta) {
+		changes = append(changes, &PolicyFieldChange{Field: "meta", From: from.Meta, To: to.Meta})
+	}
+
+	return changes
+}
+
+// diffStrings returns the strings only in `to` and the strings only in `from`.
+func diffStrings(from, to []string) (added, removed []string) {
+	fromSet := make(map[string]bool, len(from))
+	for _, s := range from {
+		fromSet[s] = true
+	}
+
+	toSet := make(map[string]bool, len(to))
+	for _, s := range to {
+		toSet[s] = true
+		if !fromSet[s] {
+			added = append(added, s)
+		}
+	}
+
+	for _, s := range from {
+		if !toSet[s] {
+			removed = append(removed, s)
+		}
+	}
+
+	return added, removed
+}
@@ -0,0 +1,237 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/pkg/log"
+	"github.com/nico612/iam-demo/pkg/storage"
+)
+
+// minRotatedTokenTTL keeps a rotated token for a while even when it is about to expire, a zero or negative
+// ttl would store it without expiration.
+const minRotatedTokenTTL = time.Second
+
+// RefreshToken describes an opaque refresh token.
+// All the refresh tokens rotated from the same login belong to one token family.
+type RefreshToken struct {
+	Username  string `json:"username"`
+	Family    string `json:"family"`
+	Rotated   bool   `json:"rotated"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+type RefreshTokenSrv interface {
+	Create(ctx context.Context, username, family string, ttl time.Duration) (string, time.Time, error)
+	Get(ctx context.Context, token string) (*RefreshToken, error)
+	Rotate(ctx context.Context, token string, ttl time.Duration) (*RefreshToken, string, time.Time, error)
+	RevokeFamily(ctx context.Context, family string)
+}
+
+// refreshTokenStorage persists the refresh tokens keyed by the hash of the token, and the hash of the active
+// token of each family.
+type refreshTokenStorage interface {
+	GetToken(hash string) (string, error)
+	SetToken(hash, value string, ttl time.Duration) error
+	DeleteToken(hash string)
+	GetFamily(family string) (string, error)
+	SetFamily(family, hash string, ttl time.Duration) error
+	// SwapFamily atomically replaces the active token of the family with newHash, it reports false
+	// when the active token is not oldHash any more.
+	SwapFamily(family, oldHash, newHash string, ttl time.Duration) (bool, error)
+	DeleteFamily(family string)
+}
+
+// refresh token 存储在 redis 中：
+// refresh-token-<hash(token)> 保存 refresh token 的信息，轮换后的 token 会被标记为 rotated 并保留到过期，用于发现重放
+// refresh-family-<family> 保存 token family 当前唯一有效的 token hash，删除后整个 family 失效.
+var (
+	refreshTokenStore  = &storage.RedisCluster{KeyPrefix: "refresh-token-"}
+	refreshFamilyStore = &storage.RedisCluster{KeyPrefix: "refresh-family-"}
+)
+
+type redisRefreshTokenStorage struct{}
+
+func (redisRefreshTokenStorage) GetToken(hash string) (string, error) {
+	return refreshTokenStore.GetKey(hash)
+}
+
+func (redisRefreshTokenStorage) SetToken(hash, value string, ttl time.Duration) error {
+	return refreshTokenStore.SetKey(hash, value, ttl)
+}
+
+func (redisRefreshTokenStorage) DeleteToken(hash string) {
+	refreshTokenStore.DeleteKey(hash)
+}
+
+func (redisRefreshTokenStorage) GetFamily(family string) (string, error) {
+	return refreshFamilyStore.GetKey(family)
+}
+
+func (redisRefreshTokenStorage) SetFamily(family, hash string, ttl time.Duration) error {
+	return refreshFamilyStore.SetKey(family, hash, ttl)
+}
+
+func (redisRefreshTokenStorage) SwapFamily(family, oldHash, newHash string, ttl time.Duration) (bool, error) {
+	return refreshFamilyStore.CompareAndSwap(family, oldHash, newHash, ttl)
+}
+
+func (redisRefreshTokenStorage) DeleteFamily(family string) {
+	refreshFamilyStore.DeleteKey(family)
+}
+
+type refreshTokenService struct {
+	store refreshTokenStorage
+}
+
+var _ RefreshTokenSrv = (*refreshTokenService)(nil)
+
+func newRefreshTokens(_ *service) *refreshTokenService {
+	return &refreshTokenService{store: redisRefreshTokenStorage{}}
+}
+
+// Create issues the first refresh token of a token family, a new family is created when family is empty.
+func (r *refreshTokenService) Create(
+	ctx context.Context,
+	username, family string,
+	ttl time.Duration,
+) (string, time.Time, error) {
+	if family == "" {
+		family = idutil.GetUUID36("")
+	}
+
+	token, expire, err := r.issue(ctx, &RefreshToken{Username: username, Family: family}, ttl)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if err := r.store.SetFamily(family, storage.HashKey(token), ttl); err != nil {
+		r.store.DeleteToken(storage.HashKey(token))
+
+		return "", time.Time{}, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return token, expire, nil
+}
+
+// Get returns the refresh token, rotated tokens included.
+func (r *refreshTokenService) Get(ctx context.Context, token string) (*RefreshToken, error) {
+	value, err := r.store.GetToken(storage.HashKey(token))
+	if err != nil {
+		return nil, errors.WithCode(code.ErrTokenInvalid, "refresh token is invalid or expired")
+	}
+
+	var rt RefreshToken
+	if err := json.Unmarshal([]byte(value), &rt); err != nil {
+		return nil, errors.WithCode(code.ErrDecodingJSON, err.Error())
+	}
+
+	return &rt, nil
+}
+
+// Rotate exchanges a refresh token for a new one of the same family. The presented token can not be used again,
+// presenting an already rotated token revokes the whole family since the token must have leaked. The active
+// token of the family is swapped atomically, so of the concurrent rotations of a token only one succeeds and
+// the others are taken as reuse.
+func (r *refreshTokenService) Rotate(
+	ctx context.Context,
+	token string,
+	ttl time.Duration,
+) (*RefreshToken, string, time.Time, error) {
+	rt, err := r.Get(ctx, token)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+
+	if rt.Rotated {
+		return nil, "", time.Time{}, r.reused(ctx, rt)
+	}
+
+	newToken, expire, err := r.issue(ctx, &RefreshToken{Username: rt.Username, Family: rt.Family}, ttl)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+
+	hash, newHash := storage.HashKey(token), storage.HashKey(newToken)
+	swapped, err := r.store.SwapFamily(rt.Family, hash, newHash, ttl)
+	if err != nil {
+		r.store.DeleteToken(newHash)
+
+		return nil, "", time.Time{}, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	if !swapped {
+		r.store.DeleteToken(newHash)
+
+		// family 仍然存在说明 token 已经被并发的请求轮换过，按重放处理
+		if _, err := r.store.GetFamily(rt.Family); err == nil {
+			return nil, "", time.Time{}, r.reused(ctx, rt)
+		}
+
+		return nil, "", time.Time{}, errors.WithCode(code.ErrTokenInvalid, "refresh token has been revoked")
+	}
+
+	// 标记为已轮换，保留到原来的过期时间，用于发现重放
+	rt.Rotated = true
+	if data, err := json.Marshal(rt); err == nil {
+		_ = r.store.SetToken(hash, string(data), rotatedTokenTTL(rt.ExpiresAt))
+	}
+
+	return rt, newToken, expire, nil
+}
+
+// RevokeFamily revokes the active refresh token of the family, so none of its tokens can be used any more.
+func (r *refreshTokenService) RevokeFamily(ctx context.Context, family string) {
+	if hash, err := r.store.GetFamily(family); err == nil {
+		r.store.DeleteToken(hash)
+	}
+
+	r.store.DeleteFamily(family)
+}
+
+// reused revokes the family of a token which is presented again after it has been rotated.
+func (r *refreshTokenService) reused(ctx context.Context, rt *RefreshToken) error {
+	log.L(ctx).Warnf("rotated refresh token of user `%s` is reused, revoke token family %s", rt.Username, rt.Family)
+	r.RevokeFamily(ctx, rt.Family)
+
+	return errors.WithCode(code.ErrTokenInvalid, "refresh token has been used")
+}
+
+// issue saves a new refresh token, the token is not active until it is set as the active token of its family.
+func (r *refreshTokenService) issue(ctx context.Context, rt *RefreshToken, ttl time.Duration) (string, time.Time, error) {
+	token, err := storage.GenerateToken("", "", storage.HashSha256)
+	if err != nil {
+		return "", time.Time{}, errors.WithCode(code.ErrUnknown, err.Error())
+	}
+
+	expire := time.Now().Add(ttl)
+	rt.ExpiresAt = expire.Unix()
+
+	data, err := json.Marshal(rt)
+	if err != nil {
+		return "", time.Time{}, errors.WithCode(code.ErrEncodingJSON, err.Error())
+	}
+
+	if err := r.store.SetToken(storage.HashKey(token), string(data), ttl); err != nil {
+		log.L(ctx).Errorf("save refresh token failed: %s", err.Error())
+
+		return "", time.Time{}, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return token, expire, nil
+}
+
+// rotatedTokenTTL returns the remaining lifetime of a rotated token, ExpiresAt is truncated to seconds so
+// the lifetime is clamped to a positive duration.
+func rotatedTokenTTL(expiresAt int64) time.Duration {
+	if ttl := time.Until(time.Unix(expiresAt, 0)); ttl > minRotatedTokenTTL {
+		return ttl
+	}
+
+	return minRotatedTokenTTL
+}
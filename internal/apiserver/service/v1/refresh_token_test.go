@@ -0,0 +1,234 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	withcode "github.com/marmotedu/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+)
+
+// memoryRefreshTokenStorage is an in-memory refreshTokenStorage, the expiration is not simulated.
+type memoryRefreshTokenStorage struct {
+	mu       sync.Mutex
+	tokens   map[string]string
+	families map[string]string
+}
+
+func newMemoryRefreshTokenStorage() *memoryRefreshTokenStorage {
+	return &memoryRefreshTokenStorage{tokens: map[string]string{}, families: map[string]string{}}
+}
+
+func (m *memoryRefreshTokenStorage) GetToken(hash string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.tokens[hash]
+	if !ok {
+		return "", errors.New("key not found")
+	}
+
+	return value, nil
+}
+
+func (m *memoryRefreshTokenStorage) SetToken(hash, value string, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tokens[hash] = value
+
+	return nil
+}
+
+func (m *memoryRefreshTokenStorage) DeleteToken(hash string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.tokens, hash)
+}
+
+func (m *memoryRefreshTokenStorage) GetFamily(family string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash, ok := m.families[family]
+	if !ok {
+		return "", errors.New("key not found")
+	}
+
+	return hash, nil
+}
+
+func (m *memoryRefreshTokenStorage) SetFamily(family, hash string, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.families[family] = hash
+
+	return nil
+}
+
+func (m *memoryRefreshTokenStorage) SwapFamily(family, oldHash, newHash string, _ time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if hash, ok := m.families[family]; !ok || hash != oldHash {
+		return false, nil
+	}
+
+	m.families[family] = newHash
+
+	return true, nil
+}
+
+func (m *memoryRefreshTokenStorage) DeleteFamily(family string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.families, family)
+}
+
+func Test_refreshTokenService_Rotate(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// prepare returns the token to rotate.
+		prepare func(t *testing.T, r *refreshTokenService) string
+		wantErr bool
+	}{
+		{
+			name: "active token",
+			prepare: func(t *testing.T, r *refreshTokenService) string {
+				token, _, err := r.Create(ctx, "admin", "family", time.Hour)
+				require.NoError(t, err)
+
+				return token
+			},
+		},
+		{
+			name: "unknown token",
+			prepare: func(t *testing.T, r *refreshTokenService) string {
+				return "unknown"
+			},
+			wantErr: true,
+		},
+		{
+			name: "reused rotated token",
+			prepare: func(t *testing.T, r *refreshTokenService) string {
+				token, _, err := r.Create(ctx, "admin", "family", time.Hour)
+				require.NoError(t, err)
+				_, _, _, err = r.Rotate(ctx, token, time.Hour)
+				require.NoError(t, err)
+
+				return token
+			},
+			wantErr: true,
+		},
+		{
+			name: "revoked family",
+			prepare: func(t *testing.T, r *refreshTokenService) string {
+				token, _, err := r.Create(ctx, "admin", "family", time.Hour)
+				require.NoError(t, err)
+				r.RevokeFamily(ctx, "family")
+
+				return token
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &refreshTokenService{store: newMemoryRefreshTokenStorage()}
+			token := tt.prepare(t, r)
+
+			rt, newToken, _, err := r.Rotate(ctx, token, time.Hour)
+			if tt.wantErr {
+				assert.True(t, withcode.IsCode(err, code.ErrTokenInvalid), err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "admin", rt.Username)
+			assert.Equal(t, "family", rt.Family)
+			assert.NotEqual(t, token, newToken)
+		})
+	}
+}
+
+func Test_refreshTokenService_Rotate_ReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	r := &refreshTokenService{store: newMemoryRefreshTokenStorage()}
+
+	token, _, err := r.Create(ctx, "admin", "family", time.Hour)
+	require.NoError(t, err)
+
+	_, newToken, _, err := r.Rotate(ctx, token, time.Hour)
+	require.NoError(t, err)
+
+	// 重放已轮换的 token 后，同一个 family 中最新的 token 也失效
+	_, _, _, err = r.Rotate(ctx, token, time.Hour)
+	require.Error(t, err)
+
+	_, _, _, err = r.Rotate(ctx, newToken, time.Hour)
+	assert.True(t, withcode.IsCode(err, code.ErrTokenInvalid), err)
+}
+
+func Test_refreshTokenService_Rotate_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	r := &refreshTokenService{store: newMemoryRefreshTokenStorage()}
+
+	token, _, err := r.Create(ctx, "admin", "family", time.Hour)
+	require.NoError(t, err)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, _, _, err := r.Rotate(ctx, token, time.Hour); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 1, succeeded)
+}
+
+func Test_rotatedTokenTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresAt int64
+		want      func(ttl time.Duration) bool
+	}{
+		{"expired", time.Now().Add(-time.Minute).Unix(), func(ttl time.Duration) bool { return ttl == minRotatedTokenTTL }},
+		{"expiring now", time.Now().Unix(), func(ttl time.Duration) bool { return ttl == minRotatedTokenTTL }},
+		{"active", time.Now().Add(time.Hour).Unix(), func(ttl time.Duration) bool { return ttl > time.Minute }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ttl := rotatedTokenTTL(tt.expiresAt)
+			assert.True(t, tt.want(ttl), ttl)
+		})
+	}
+}
@@ -1 +1,118 @@
 package v1
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/apiserver/store"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/notification"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// SecretSrv defines functions used to handle secret request. Secrets are identified by their name
+// under the user, every change notifies iam-authz-server to refetch the secret.
+type SecretSrv interface {
+	Create(ctx context.Context, secret *v1.Secret, opts metav1.CreateOptions) error
+	Update(ctx context.Context, secret *v1.Secret, opts metav1.UpdateOptions) error
+	Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, username string, names []string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, username, name string, opts metav1.GetOptions) (*v1.Secret, error)
+	List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.SecretList, error)
+}
+
+type secretService struct {
+	store store.Factory
+}
+
+var _ SecretSrv = (*secretService)(nil)
+
+func newSecrets(srv *service) *secretService {
+	return &secretService{store: srv.store}
+}
+
+func (s *secretService) Create(ctx context.Context, secret *v1.Secret, opts metav1.CreateOptions) error {
+	if err := s.store.Secrets().Create(ctx, secret, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	notify(ctx, notification.NewSecretChangedNotification(secret.Username, secret.SecretID))
+
+	return nil
+}
+
+func (s *secretService) Update(ctx context.Context, secret *v1.Secret, opts metav1.UpdateOptions) error {
+	if err := s.store.Secrets().Update(ctx, secret, opts); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	notify(ctx, notification.NewSecretChangedNotification(secret.Username, secret.SecretID))
+
+	return nil
+}
+
+// Delete deletes the secret, iam-authz-server drops it from the cache with the secret id.
+func (s *secretService) Delete(ctx context.Context, username, name string, opts metav1.DeleteOptions) error {
+	secret, err := s.store.Secrets().Get(ctx, username, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.Secrets().Delete(ctx, username, name, opts); err != nil {
+		return err
+	}
+
+	notify(ctx, notification.NewSecretChangedNotification(username, secret.SecretID))
+
+	return nil
+}
+
+// DeleteCollection deletes the secrets by name, one notification is published for each deleted secret.
+func (s *secretService) DeleteCollection(
+	ctx context.Context,
+	username string,
+	names []string,
+	opts metav1.DeleteOptions,
+) error {
+	secretIDs := make([]string, 0, len(names))
+	for _, name := range names {
+		secret, err := s.store.Secrets().Get(ctx, username, name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsCode(err, code.ErrSecretNotFound) {
+				continue
+			}
+
+			return err
+		}
+
+		secretIDs = append(secretIDs, secret.SecretID)
+	}
+
+	if err := s.store.Secrets().DeleteCollection(ctx, username, names, opts); err != nil {
+		log.L(ctx).Errorf("delete secrets of user %s failed: %s", username, err.Error())
+
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	for _, secretID := range secretIDs {
+		notify(ctx, notification.NewSecretChangedNotification(username, secretID))
+	}
+
+	return nil
+}
+
+func (s *secretService) Get(ctx context.Context, username, name string, opts metav1.GetOptions) (*v1.Secret, error) {
+	return s.store.Secrets().Get(ctx, username, name, opts)
+}
+
+func (s *secretService) List(ctx context.Context, username string, opts metav1.ListOptions) (*v1.SecretList, error) {
+	secrets, err := s.store.Secrets().List(ctx, username, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return secrets, nil
+}
@@ -4,6 +4,12 @@ import "github.com/nico612/iam-demo/internal/apiserver/store"
 
 type Service interface {
 	Users() UserSrv
+	APIKeys() APIKeySrv
+	Secrets() SecretSrv
+	RefreshTokens() RefreshTokenSrv
+	Sessions() SessionSrv
+	Policies() PolicySrv
+	Tenants() TenantSrv
 }
 
 var _ Service = &service{}
@@ -19,3 +25,27 @@ func NewService(store store.Factory) Service {
 func (s *service) Users() UserSrv {
 	return newUsers(s)
 }
+
+func (s *service) APIKeys() APIKeySrv {
+	return newAPIKeys(s)
+}
+
+func (s *service) Secrets() SecretSrv {
+	return newSecrets(s)
+}
+
+func (s *service) RefreshTokens() RefreshTokenSrv {
+	return newRefreshTokens(s)
+}
+
+func (s *service) Sessions() SessionSrv {
+	return newSessions(s)
+}
+
+func (s *service) Policies() PolicySrv {
+	return newPolicies(s)
+}
+
+func (s *service) Tenants() TenantSrv {
+	return newTenants(s)
+}
@@ -0,0 +1,190 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/pkg/log"
+	"github.com/nico612/iam-demo/pkg/storage"
+)
+
+// sessionTouchInterval limits how often the last-seen time of a session is written back to redis.
+const sessionTouchInterval = time.Minute
+
+// Session describes a login of a user. The session id is also the family id of the refresh tokens
+// issued for the login, so revoking a session revokes its refresh tokens as well.
+type Session struct {
+	ID         string    `json:"id"`
+	Username   string    `json:"username"`
+	UserAgent  string    `json:"userAgent"`
+	ClientIP   string    `json:"clientIP"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// SessionList is the whole list of active sessions of a user.
+type SessionList struct {
+	TotalCount int64      `json:"totalCount"`
+	Items      []*Session `json:"items"`
+}
+
+type SessionSrv interface {
+	Create(ctx context.Context, session *Session, ttl time.Duration) error
+	Get(ctx context.Context, id string) (*Session, error)
+	Touch(ctx context.Context, session *Session, ttl time.Duration) error
+	List(ctx context.Context, username string) (*SessionList, error)
+	Delete(ctx context.Context, username, id string) error
+	DeleteCollection(ctx context.Context, username string) error
+}
+
+// session 存储在 redis 中：
+// session-<id> 保存 session 信息，过期时间和 refresh token 的有效期一致
+// session-index-<username> 是一个集合，保存用户所有 session 的 id.
+var (
+	sessionStore = &storage.RedisCluster{KeyPrefix: "session-"}
+	sessionIndex = &storage.RedisCluster{KeyPrefix: "session-index-"}
+)
+
+type sessionService struct {
+	srv *service
+}
+
+var _ SessionSrv = (*sessionService)(nil)
+
+func newSessions(srv *service) *sessionService {
+	return &sessionService{srv: srv}
+}
+
+// Create saves a new session, session.ID is generated when it is empty.
+func (s *sessionService) Create(ctx context.Context, session *Session, ttl time.Duration) error {
+	if session.ID == "" {
+		session.ID = idutil.GetUUID36("")
+	}
+
+	now := time.Now()
+	session.CreatedAt = now
+	session.LastSeenAt = now
+
+	if err := s.save(session, ttl); err != nil {
+		log.L(ctx).Errorf("save session failed: %s", err.Error())
+
+		return err
+	}
+
+	if err := sessionIndex.AddToSet(session.Username, session.ID); err != nil {
+		log.L(ctx).Errorf("index session failed: %s", err.Error())
+		sessionStore.DeleteKey(session.ID)
+
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+// Get returns an active session, revoked and expired sessions are reported as ErrSessionNotFound.
+func (s *sessionService) Get(ctx context.Context, id string) (*Session, error) {
+	value, err := sessionStore.GetKey(id)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrSessionNotFound, "session %s not found", id)
+	}
+
+	var session Session
+	if err := json.Unmarshal([]byte(value), &session); err != nil {
+		return nil, errors.WithCode(code.ErrDecodingJSON, err.Error())
+	}
+
+	return &session, nil
+}
+
+// Touch updates the last-seen time of the session. The lifetime of the session is extended by ttl,
+// a zero ttl keeps the current expire time. Writes are throttled when the lifetime is not extended.
+func (s *sessionService) Touch(ctx context.Context, session *Session, ttl time.Duration) error {
+	if ttl == 0 && time.Since(session.LastSeenAt) < sessionTouchInterval {
+		return nil
+	}
+
+	session.LastSeenAt = time.Now()
+	if ttl == 0 {
+		ttl = time.Until(session.ExpiresAt)
+		if ttl <= 0 {
+			return nil
+		}
+	}
+
+	return s.save(session, ttl)
+}
+
+// List returns the active sessions of the user.
+func (s *sessionService) List(ctx context.Context, username string) (*SessionList, error) {
+	ids, err := sessionIndex.GetSet(username)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	items := make([]*Session, 0, len(ids))
+	for _, id := range ids {
+		session, err := s.Get(ctx, id)
+		if err != nil {
+			// the session has expired, drop the dangling index entry.
+			sessionIndex.RemoveFromSet(username, id)
+
+			continue
+		}
+
+		items = append(items, session)
+	}
+
+	return &SessionList{TotalCount: int64(len(items)), Items: items}, nil
+}
+
+// Delete revokes a session of the user together with its refresh tokens.
+func (s *sessionService) Delete(ctx context.Context, username, id string) error {
+	if !sessionIndex.IsMemberOfSet(username, id) {
+		return errors.WithCode(code.ErrSessionNotFound, "session %s not found", id)
+	}
+
+	s.revoke(ctx, username, id)
+
+	return nil
+}
+
+// DeleteCollection revokes all the sessions of the user.
+func (s *sessionService) DeleteCollection(ctx context.Context, username string) error {
+	ids, err := sessionIndex.GetSet(username)
+	if err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	for _, id := range ids {
+		s.revoke(ctx, username, id)
+	}
+
+	return nil
+}
+
+func (s *sessionService) revoke(ctx context.Context, username, id string) {
+	sessionStore.DeleteKey(id)
+	sessionIndex.RemoveFromSet(username, id)
+	s.srv.RefreshTokens().RevokeFamily(ctx, id)
+}
+
+func (s *sessionService) save(session *Session, ttl time.Duration) error {
+	session.ExpiresAt = time.Now().Add(ttl)
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return errors.WithCode(code.ErrEncodingJSON, err.Error())
+	}
+
+	if err := sessionStore.SetKey(session.ID, string(data), ttl); err != nil {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
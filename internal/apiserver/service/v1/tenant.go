@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"context"
+	"regexp"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/apiserver/store"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+type TenantSrv interface {
+	Create(ctx context.Context, tenant *store.Tenant, opts metav1.CreateOptions) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*store.Tenant, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*store.TenantList, error)
+}
+
+type tenantService struct {
+	store store.Factory
+}
+
+var _ TenantSrv = (*tenantService)(nil)
+
+func newTenants(srv *service) *tenantService {
+	return &tenantService{store: srv.store}
+}
+
+func (t *tenantService) Create(ctx context.Context, tenant *store.Tenant, opts metav1.CreateOptions) error {
+	if err := t.store.Tenants().Create(ctx, tenant, opts); err != nil {
+		if match, _ := regexp.MatchString("Duplicate entry '.*' for key", err.Error()); match {
+			return errors.WithCode(code.ErrTenantAlreadyExist, err.Error())
+		}
+
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+// Delete deletes the tenant, a tenant can only be deleted after all its users are deleted.
+func (t *tenantService) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	if _, err := t.store.Tenants().Get(ctx, name, metav1.GetOptions{}); err != nil {
+		return err
+	}
+
+	// 禁用的用户同样属于租户，需要统计所有状态的用户
+	count, err := t.store.Tenants().CountUsers(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.WithCode(code.ErrTenantNotEmpty, "tenant %s still has %d users", name, count)
+	}
+
+	if err := t.store.Tenants().Delete(ctx, name, opts); err != nil {
+		log.L(ctx).Errorf("delete tenant %s failed: %s", name, err.Error())
+
+		return err
+	}
+
+	return nil
+}
+
+func (t *tenantService) Get(ctx context.Context, name string, opts metav1.GetOptions) (*store.Tenant, error) {
+	return t.store.Tenants().Get(ctx, name, opts)
+}
+
+func (t *tenantService) List(ctx context.Context, opts metav1.ListOptions) (*store.TenantList, error) {
+	tenants, err := t.store.Tenants().List(ctx, opts)
+	if err != nil {
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return tenants, nil
+}
@@ -24,13 +24,14 @@ type UserSrv interface {
 }
 
 type userService struct {
+	srv   *service
 	store store.Factory
 }
 
 var _ UserSrv = (*userService)(nil)
 
 func newUsers(srv *service) *userService {
-	return &userService{store: srv.store}
+	return &userService{srv: srv, store: srv.store}
 }
 
 // List returns user list in the storage. This function has a good performance.
@@ -134,9 +135,16 @@ func (u *userService) ListWithBadPerformance(ctx context.Context, opts metav1.Li
 	return &v1.UserList{ListMeta: users.ListMeta, Items: infos}, nil
 }
 
+// Create creates the user, the tenant of the user must exist.
 func (u *userService) Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error {
+	if tenant := store.TenantOf(user.Name); tenant != store.DefaultTenant {
+		if _, err := u.store.Tenants().Get(ctx, tenant, metav1.GetOptions{}); err != nil {
+			return err
+		}
+	}
+
 	if err := u.store.Users().Create(ctx, user, opts); err != nil {
-		if match, _ := regexp.MatchString("Duplicate entry '.*' for key 'idx_name'", err.Error()); match {
+		if match, _ := regexp.MatchString("Duplicate entry '.*' for key '.*idx_(tenant_)?name'", err.Error()); match {
 			return errors.WithCode(code.ErrUserAlreadyExist, err.Error())
 		}
 
@@ -151,6 +159,12 @@ func (u *userService) DeleteCollection(ctx context.Context, usernames []string,
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
 
+	for _, username := range usernames {
+		if err := u.srv.APIKeys().DeleteCollection(ctx, username); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -159,7 +173,8 @@ func (u *userService) Delete(ctx context.Context, username string, opts metav1.D
 		return err
 	}
 
-	return nil
+	// 删除用户后吊销其 api key
+	return u.srv.APIKeys().DeleteCollection(ctx, username)
 }
 
 func (u *userService) Get(ctx context.Context, username string, opts metav1.GetOptions) (*v1.User, error) {
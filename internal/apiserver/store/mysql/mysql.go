@@ -40,6 +40,14 @@ func (ds *datastore) Policies() store.PolicyStore {
 	return newPolicies(ds)
 }
 
+func (ds *datastore) PolicyRevisions() store.PolicyRevisionStore {
+	return newPolicyRevisions(ds)
+}
+
+func (ds *datastore) Tenants() store.TenantStore {
+	return newTenants(ds)
+}
+
 func (ds *datastore) Close() error {
 
 	db, err := ds.db.DB()
@@ -78,7 +86,7 @@ func GetMySQLFactoryOr(opts *options.MySQLOptions) (store.Factory, error) {
 		dbIns, err = db.New(options)
 
 		// uncomment the following line if you need auto migration the given models
-		// not suggested in production environment.
+		// not suggested in production environment, apply the sql files in configs/migrations instead.
 		// migrateDatabase(dbIns)
 
 		mysqlFactory = &datastore{dbIns}
@@ -103,6 +111,12 @@ func cleanDatabase(db *gorm.DB) error {
 	if err := db.Migrator().DropTable(&v1.Secret{}); err != nil {
 		return errors.Wrap(err, "drop secret table failed")
 	}
+	if err := db.Migrator().DropTable(&store.PolicyRevision{}); err != nil {
+		return errors.Wrap(err, "drop policy revision table failed")
+	}
+	if err := db.Migrator().DropTable(&store.Tenant{}); err != nil {
+		return errors.Wrap(err, "drop tenant table failed")
+	}
 
 	return nil
 }
@@ -111,12 +125,34 @@ func cleanDatabase(db *gorm.DB) error {
 // won't delete/change current data.
 // nolint:unused // may be reused in the feature, or just show a migrate usage.
 func migrateDatabase(db *gorm.DB) error {
-	if err := db.AutoMigrate(&v1.User{}); err != nil {
+	if err := db.AutoMigrate(&tenantUser{}); err != nil {
 		return errors.Wrap(err, "migrate user model failed")
 	}
+	// 用户名在租户内唯一
+	if db.Migrator().HasIndex(&v1.User{}, "idx_name") {
+		if err := db.Migrator().DropIndex(&v1.User{}, "idx_name"); err != nil {
+			return errors.Wrap(err, "drop user name index failed")
+		}
+	}
+	if !db.Migrator().HasIndex(&v1.User{}, "idx_tenant_name") {
+		if err := db.Exec("CREATE UNIQUE INDEX idx_tenant_name ON user (tenant, name)").Error; err != nil {
+			return errors.Wrap(err, "create user tenant name index failed")
+		}
+	}
+	if err := db.AutoMigrate(&store.Tenant{}); err != nil {
+		return errors.Wrap(err, "migrate tenant model failed")
+	}
+	if !db.Migrator().HasIndex(&store.Tenant{}, "idx_name") {
+		if err := db.Exec("CREATE UNIQUE INDEX idx_name ON tenant (name)").Error; err != nil {
+			return errors.Wrap(err, "create tenant name index failed")
+		}
+	}
 	if err := db.AutoMigrate(&v1.Policy{}); err != nil {
 		return errors.Wrap(err, "migrate policy model failed")
 	}
+	if err := db.AutoMigrate(&store.PolicyRevision{}); err != nil {
+		return errors.Wrap(err, "migrate policy revision model failed")
+	}
 	if err := db.AutoMigrate(&v1.Secret{}); err != nil {
 		return errors.Wrap(err, "migrate secret model failed")
 	}
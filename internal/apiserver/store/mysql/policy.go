@@ -104,7 +104,7 @@ func (p *policies) List(ctx context.Context, username string, opts metav1.ListOp
 	selector, _ := fields.ParseSelector(opts.FieldSelector)
 	name, _ := selector.RequiresExactMatch("name")
 
-	d := p.db.Where("name like ?", "%"+name+"%").
+	d := whereTenant(p.db, "username", selector).Where("name like ?", "%"+name+"%").
 		Offset(ol.Offset).
 		Limit(ol.Limit).
 		Order("id desc").
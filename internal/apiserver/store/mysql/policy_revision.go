@@ -0,0 +1,102 @@
+package mysql
+
+import (
+	"context"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"gorm.io/gorm"
+
+	"github.com/nico612/iam-demo/internal/apiserver/store"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/util/gormutil"
+)
+
+type policyRevisions struct {
+	db *gorm.DB
+}
+
+var _ store.PolicyRevisionStore = (*policyRevisions)(nil)
+
+func newPolicyRevisions(ds *datastore) *policyRevisions {
+	return &policyRevisions{ds.db}
+}
+
+// Commit creates or updates the policy and appends the revision with the next version of the policy.
+func (r *policyRevisions) Commit(ctx context.Context, policy *v1.Policy, revision *store.PolicyRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if policy.ID == 0 {
+			if err := tx.Create(policy).Error; err != nil {
+				return err
+			}
+		} else {
+			// 锁住策略，保证同一策略的修改按顺序生成版本号
+			if err := tx.Exec("select id from policy where id = ? for update", policy.ID).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Save(policy).Error; err != nil {
+				return err
+			}
+		}
+
+		var version int
+		err := tx.Model(&store.PolicyRevision{}).
+			Where("username = ? and policyName = ?", policy.Username, policy.Name).
+			Select("coalesce(max(version), 0)").
+			Scan(&version).Error
+		if err != nil {
+			return err
+		}
+
+		revision.Username = policy.Username
+		revision.PolicyName = policy.Name
+		revision.Version = version + 1
+		revision.PolicyShadow = policy.PolicyShadow
+
+		return tx.Create(revision).Error
+	})
+}
+
+// Get return the revision of the policy by the version.
+func (r *policyRevisions) Get(
+	ctx context.Context,
+	username, name string,
+	version int,
+) (*store.PolicyRevision, error) {
+	revision := &store.PolicyRevision{}
+
+	err := r.db.Where("username = ? and policyName = ? and version = ?", username, name, version).
+		First(revision).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrPolicyRevisionNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return revision, nil
+}
+
+// List return the revisions of the policy, the latest first.
+func (r *policyRevisions) List(
+	ctx context.Context,
+	username, name string,
+	opts metav1.ListOptions,
+) (*store.PolicyRevisionList, error) {
+	ret := &store.PolicyRevisionList{}
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+
+	d := r.db.Where("username = ? and policyName = ?", username, name).
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("version desc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
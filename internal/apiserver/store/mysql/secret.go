@@ -46,7 +46,7 @@ func (s *secrets) DeleteCollection(ctx context.Context, username string, names [
 	if opts.Unscoped {
 		s.db = s.db.Unscoped()
 	}
-	return s.db.Where("username = ? and names in (?)", username, names).Delete(&v1.Secret{}).Error
+	return s.db.Where("username = ? and name in (?)", username, names).Delete(&v1.Secret{}).Error
 }
 
 func (s *secrets) Get(ctx context.Context, username, name string, opts metav1.GetOptions) (*v1.Secret, error) {
@@ -73,7 +73,7 @@ func (s *secrets) List(ctx context.Context, username string, opts metav1.ListOpt
 	selector, _ := fields.ParseSelector(opts.FieldSelector)
 	name, _ := selector.RequiresExactMatch("name")
 
-	d := s.db.Where("name like ?", "%"+name+"%").
+	d := whereTenant(s.db, "username", selector).Where("name like ?", "%"+name+"%").
 		Offset(ol.Offset).
 		Limit(ol.Limit).
 		Order("id desc").
@@ -0,0 +1,107 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/marmotedu/component-base/pkg/fields"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/errors"
+	"gorm.io/gorm"
+
+	"github.com/nico612/iam-demo/internal/apiserver/store"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/util/gormutil"
+)
+
+type tenants struct {
+	db *gorm.DB
+}
+
+var _ store.TenantStore = &tenants{}
+
+func newTenants(ds *datastore) *tenants {
+	return &tenants{db: ds.db}
+}
+
+// Create creates a new tenant.
+func (t *tenants) Create(ctx context.Context, tenant *store.Tenant, opts metav1.CreateOptions) error {
+	return t.db.Create(tenant).Error
+}
+
+// Delete deletes the tenant by the tenant name.
+func (t *tenants) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	db := t.db
+	if opts.Unscoped {
+		db = db.Unscoped()
+	}
+
+	err := db.Where("name = ?", name).Delete(&store.Tenant{}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return nil
+}
+
+// Get return a tenant by the tenant name.
+func (t *tenants) Get(ctx context.Context, name string, opts metav1.GetOptions) (*store.Tenant, error) {
+	tenant := &store.Tenant{}
+
+	err := t.db.Where("name = ?", name).First(tenant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.WithCode(code.ErrTenantNotFound, err.Error())
+		}
+
+		return nil, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return tenant, nil
+}
+
+// List return all tenants.
+func (t *tenants) List(ctx context.Context, opts metav1.ListOptions) (*store.TenantList, error) {
+	ret := &store.TenantList{}
+	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
+
+	selector, _ := fields.ParseSelector(opts.FieldSelector)
+	name, _ := selector.RequiresExactMatch("name")
+
+	d := t.db.Where("name like ?", "%"+name+"%").
+		Offset(ol.Offset).
+		Limit(ol.Limit).
+		Order("id desc").
+		Find(&ret.Items).
+		Offset(-1).
+		Limit(-1).
+		Count(&ret.TotalCount)
+
+	return ret, d.Error
+}
+
+// CountUsers returns the number of the rows of the tenant in the user table, the users of any status
+// are counted.
+func (t *tenants) CountUsers(ctx context.Context, name string) (int64, error) {
+	var count int64
+	if err := t.db.Model(&tenantUser{}).Where("tenant = ?", name).Count(&count).Error; err != nil {
+		return 0, errors.WithCode(code.ErrDatabase, err.Error())
+	}
+
+	return count, nil
+}
+
+// whereTenant limits the query to the rows owned by the users of the tenant selected by the `tenant` field
+// selector, column is the column keeping the username of the owner.
+func whereTenant(db *gorm.DB, column string, selector fields.Selector) *gorm.DB {
+	tenant, found := selector.RequiresExactMatch("tenant")
+	if !found {
+		return db
+	}
+
+	// 默认租户的用户名不带租户前缀
+	if tenant == store.DefaultTenant {
+		return db.Where(column+" not like ?", "%/%")
+	}
+
+	return db.Where(column+" like ?", store.QualifyUsername(tenant, "%"))
+}
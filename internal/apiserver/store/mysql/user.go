@@ -12,6 +12,30 @@ import (
 	"gorm.io/gorm"
 )
 
+// tenantUser maps a user to the user table together with its tenant. The name of v1.User is the
+// qualified username, while the name column only keeps the name of the user in the tenant.
+type tenantUser struct {
+	v1.User
+
+	Tenant string `gorm:"column:tenant;type:varchar(64);not null;default:default"`
+}
+
+// newTenantUser converts a user to the row stored in the user table.
+func newTenantUser(user *v1.User) *tenantUser {
+	tu := &tenantUser{User: *user}
+	tu.Tenant, tu.Name = store.SplitUsername(user.Name)
+
+	return tu
+}
+
+// user converts the row back to a user, the name of the user is qualified by the tenant.
+func (tu *tenantUser) user() *v1.User {
+	user := tu.User
+	user.Name = store.QualifyUsername(tu.Tenant, tu.Name)
+
+	return &user
+}
+
 type users struct {
 	db *gorm.DB
 }
@@ -24,12 +48,19 @@ func newUsers(ds *datastore) store.UserStore {
 
 // Create creates a new user account.
 func (u *users) Create(ctx context.Context, user *v1.User, opts metav1.CreateOptions) error {
-	return u.db.Create(&user).Error
+	tu := newTenantUser(user)
+	if err := u.db.Create(tu).Error; err != nil {
+		return err
+	}
+
+	*user = *tu.user()
+
+	return nil
 }
 
 // Update updates an user account information.
 func (u *users) Update(ctx context.Context, user *v1.User, opts metav1.UpdateOptions) error {
-	return u.db.Save(user).Error
+	return u.db.Save(newTenantUser(user)).Error
 }
 
 // Delete deletes the user by the user identifier.
@@ -45,7 +76,9 @@ func (u *users) Delete(ctx context.Context, username string, opts metav1.DeleteO
 		u.db = u.db.Unscoped() // 永久删除
 	}
 
-	if err := u.db.Where("name = ?", username).Delete(&v1.User{}).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+	tenant, name := store.SplitUsername(username)
+	err := u.db.Where("tenant = ? and name = ?", tenant, name).Delete(&v1.User{}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.WithCode(code.ErrDatabase, err.Error())
 	}
 	return nil
@@ -64,14 +97,27 @@ func (u *users) DeleteCollection(ctx context.Context, usernames []string, opts m
 		u.db = u.db.Unscoped()
 	}
 
-	return u.db.Where("name in (?)").Delete(&v1.User{}).Error
+	// 按租户分组删除
+	names := make(map[string][]string)
+	for _, username := range usernames {
+		tenant, name := store.SplitUsername(username)
+		names[tenant] = append(names[tenant], name)
+	}
+
+	for tenant, list := range names {
+		if err := u.db.Where("tenant = ? and name in (?)", tenant, list).Delete(&v1.User{}).Error; err != nil {
+			return err
+		}
+	}
 
+	return nil
 }
 
 func (u *users) Get(ctx context.Context, username string, opts metav1.GetOptions) (*v1.User, error) {
+	tenant, name := store.SplitUsername(username)
 
-	user := &v1.User{}
-	err := u.db.Where("name = ? and status = 1", username).First(&user).Error
+	tu := &tenantUser{}
+	err := u.db.Where("tenant = ? and name = ? and status = 1", tenant, name).First(tu).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.WithCode(code.ErrUserNotFound, err.Error())
@@ -79,10 +125,10 @@ func (u *users) Get(ctx context.Context, username string, opts metav1.GetOptions
 		return nil, errors.WithCode(code.ErrDatabase, err.Error())
 	}
 
-	return user, nil
+	return tu.user(), nil
 }
 
-// List return all users.
+// List return all users, the users of one tenant are listed with the `tenant` field selector.
 func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList, error) {
 	ret := &v1.UserList{}
 	ol := gormutil.Unpointer(opts.Offset, opts.Limit)
@@ -90,15 +136,26 @@ func (u *users) List(ctx context.Context, opts metav1.ListOptions) (*v1.UserList
 	selector, _ := fields.ParseSelector(opts.FieldSelector)
 	username, _ := selector.RequiresExactMatch("name")
 
-	d := u.db.Where("name like ? and status = 1", "%"+username+"%").
+	db := u.db
+	if tenant, found := selector.RequiresExactMatch("tenant"); found {
+		db = db.Where("tenant = ?", tenant)
+	}
+
+	var items []*tenantUser
+	d := db.Where("name like ? and status = 1", "%"+username+"%").
 		Offset(ol.Offset).
 		Limit(ol.Limit).
 		Order("id desc").
-		Find(&ret.Items).
+		Find(&items).
 		Offset(-1).
 		Limit(-1).
 		Count(&ret.TotalCount)
 
+	ret.Items = make([]*v1.User, 0, len(items))
+	for _, item := range items {
+		ret.Items = append(ret.Items, item.user())
+	}
+
 	return ret, d.Error
 
 }
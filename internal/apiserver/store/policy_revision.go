@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/marmotedu/api/apiserver/v1"
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+)
+
+// PolicyRevision is an immutable snapshot of a policy, saved every time the policy is created, updated
+// or rolled back. The versions of the revisions of a policy increase monotonically from 1.
+type PolicyRevision struct {
+	ID uint64 `json:"id,omitempty" gorm:"primary_key;AUTO_INCREMENT;column:id"`
+
+	// The user of the policy.
+	Username string `json:"username" gorm:"column:username;type:varchar(255);uniqueIndex:idx_policy_version"`
+
+	// The name of the policy.
+	PolicyName string `json:"policyName" gorm:"column:policyName;type:varchar(255);uniqueIndex:idx_policy_version"`
+
+	Version int `json:"version" gorm:"column:version;uniqueIndex:idx_policy_version"`
+
+	// The user who made the change.
+	Author string `json:"author" gorm:"column:author"`
+
+	Message string `json:"message" gorm:"column:message;type:varchar(255)"`
+
+	// The ladon policy content of the revision, in the same format as the policyShadow of the policy.
+	PolicyShadow string `json:"policyShadow" gorm:"column:policyShadow"`
+
+	CreatedAt time.Time `json:"createdAt,omitempty" gorm:"column:createdAt"`
+}
+
+// PolicyRevisionList is the list of the revisions of a policy.
+type PolicyRevisionList struct {
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	Items []*PolicyRevision `json:"items"`
+}
+
+// TableName maps to mysql table name.
+func (r *PolicyRevision) TableName() string {
+	return "policy_revision"
+}
+
+// PolicyRevisionStore defines the policy revision storage interface.
+type PolicyRevisionStore interface {
+	// Commit creates or updates the policy and appends the revision with the next version of the policy,
+	// both in one transaction. The version and the content of the revision are filled by Commit.
+	Commit(ctx context.Context, policy *v1.Policy, revision *PolicyRevision) error
+	Get(ctx context.Context, username, name string, version int) (*PolicyRevision, error)
+	List(ctx context.Context, username, name string, opts metav1.ListOptions) (*PolicyRevisionList, error)
+}
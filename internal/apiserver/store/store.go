@@ -5,6 +5,8 @@ type Factory interface {
 	Secrets() SecretStore
 	Policies() PolicyStore
 	PolicyAudits() PolicyAuditStore
+	PolicyRevisions() PolicyRevisionStore
+	Tenants() TenantStore
 	Close() error
 }
 
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	metav1 "github.com/marmotedu/component-base/pkg/meta/v1"
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/component-base/pkg/validation"
+	"github.com/marmotedu/component-base/pkg/validation/field"
+	"gorm.io/gorm"
+)
+
+// 租户：每个用户属于一个租户，用户名在租户内唯一。默认租户的用户名不带租户前缀，
+// 其他租户的用户使用 <tenant>/<name> 形式的完整用户名，secrets、policies 和授权缓存都以完整用户名区分，
+// 因此不同租户的数据不会混在一起.
+
+// DefaultTenant is the tenant of the users whose username is not qualified by a tenant.
+// Its administrators are the administrators of the whole system.
+const DefaultTenant = "default"
+
+// tenantSeparator separates the tenant from the name of a qualified username.
+const tenantSeparator = "/"
+
+// Tenant represents an organization which owns users, and the secrets and policies of the users.
+type Tenant struct {
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	DisplayName string `json:"displayName" gorm:"column:displayName" validate:"omitempty,max=64"`
+
+	Description string `json:"description" gorm:"column:description" validate:"description"`
+}
+
+// TenantList is the whole list of all tenants which have been stored in storage.
+type TenantList struct {
+	// Standard list metadata.
+	metav1.ListMeta `json:",inline"`
+
+	Items []*Tenant `json:"items"`
+}
+
+// TableName maps to mysql table name.
+func (t *Tenant) TableName() string {
+	return "tenant"
+}
+
+// AfterCreate run after create database record.
+func (t *Tenant) AfterCreate(tx *gorm.DB) error {
+	t.InstanceID = idutil.GetInstanceID(t.ID, "tenant-")
+
+	return tx.Save(t).Error
+}
+
+// Validate validates that a tenant object is valid. The name of a tenant is a DNS label, so that
+// it can prefix the names of its users.
+func (t *Tenant) Validate() field.ErrorList {
+	allErrs := validation.NewValidator(t).Validate()
+
+	for _, msg := range validation.IsDNS1123Label(t.Name) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), t.Name, msg))
+	}
+
+	if t.Name == DefaultTenant {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), t.Name, "is reserved"))
+	}
+
+	return allErrs
+}
+
+// TenantStore defines the tenant storage interface.
+type TenantStore interface {
+	Create(ctx context.Context, tenant *Tenant, opts metav1.CreateOptions) error
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*Tenant, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*TenantList, error)
+	// CountUsers returns the number of the users of the tenant, whatever their status is.
+	CountUsers(ctx context.Context, name string) (int64, error)
+}
+
+// QualifyUsername returns the username of the user with the given name in the tenant.
+func QualifyUsername(tenant, name string) string {
+	if tenant == "" || tenant == DefaultTenant {
+		return name
+	}
+
+	return tenant + tenantSeparator + name
+}
+
+// SplitUsername splits a username into the tenant and the name of the user in the tenant.
+func SplitUsername(username string) (tenant, name string) {
+	if i := strings.Index(username, tenantSeparator); i >= 0 {
+		return username[:i], username[i+1:]
+	}
+
+	return DefaultTenant, username
+}
+
+// TenantOf returns the tenant of the user.
+func TenantOf(username string) string {
+	tenant, _ := SplitUsername(username)
+
+	return tenant
+}
@@ -25,6 +25,8 @@ type AnalyticsRecord struct {
 	Request    string    `json:"request"`    // 请求
 	Policies   string    `json:"policies"`   // 策略
 	Deciders   string    `json:"deciders"`
+	ReasonCode int       `json:"reasonCode"`                 // 拒绝原因码，允许访问时为 0
+	Policy     string    `json:"policy"`                     // 决定拒绝的策略
 	ExpireAt   time.Time `json:"expireAt"   bson:"expireAt"` // 缓存过期时间
 }
 
@@ -0,0 +1,72 @@
+package authorization
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// AuthorizationOptions contains configuration items related to authorization.
+type AuthorizationOptions struct {
+	MaxBatchSize      int           `json:"max-batch-size"      mapstructure:"max-batch-size"`      // 批量授权单次请求最多包含的授权请求数
+	DecisionCacheSize int64         `json:"decision-cache-size" mapstructure:"decision-cache-size"` // 授权结果缓存的最大条目数，0 表示不缓存
+	DecisionCacheTTL  time.Duration `json:"decision-cache-ttl"  mapstructure:"decision-cache-ttl"`  // 授权结果缓存的有效期
+}
+
+// NewAuthorizationOptions creates a AuthorizationOptions object with default parameters.
+func NewAuthorizationOptions() *AuthorizationOptions {
+	return &AuthorizationOptions{
+		MaxBatchSize:      100,
+		DecisionCacheSize: 100000,
+		DecisionCacheTTL:  5 * time.Second,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *AuthorizationOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	errors := []error{}
+
+	if o.MaxBatchSize < 1 {
+		errors = append(errors, fmt.Errorf("--authorization.max-batch-size %v must be greater than 0", o.MaxBatchSize))
+	}
+
+	if o.DecisionCacheSize < 0 {
+		errors = append(
+			errors,
+			fmt.Errorf("--authorization.decision-cache-size %v must not be negative", o.DecisionCacheSize),
+		)
+	}
+
+	if o.DecisionCacheSize > 0 && o.DecisionCacheTTL <= 0 {
+		errors = append(
+			errors,
+			fmt.Errorf("--authorization.decision-cache-ttl %v must be greater than 0", o.DecisionCacheTTL),
+		)
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to authorization for a specific server to the
+// specified FlagSet.
+func (o *AuthorizationOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.IntVar(&o.MaxBatchSize, "authorization.max-batch-size", o.MaxBatchSize,
+		"Maximum number of requests accepted by one batch authorization call.")
+
+	fs.Int64Var(&o.DecisionCacheSize, "authorization.decision-cache-size", o.DecisionCacheSize, ""+
+		"Maximum number of authorization decisions to cache, repeated requests are served from the cache. "+
+		"Set to zero to disable the decision cache.")
+
+	fs.DurationVar(&o.DecisionCacheTTL, "authorization.decision-cache-ttl", o.DecisionCacheTTL, ""+
+		"How long a cached authorization decision is served. All the decisions are dropped when "+
+		"the secrets and policies are reloaded.")
+}
@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/nico612/iam-demo/internal/authzserver/analytics"
-	"github.com/nico612/iam-demo/internal/authzserver/authorization"
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
 	"github.com/ory/ladon"
 	"strings"
 	"time"
@@ -15,11 +15,23 @@ type PolicyGetter interface {
 	GetPolicy(key string) ([]*ladon.DefaultPolicy, error)
 }
 
+// PolicyIndexGetter is implemented by the policy getters which keep prebuilt policy indexes.
+type PolicyIndexGetter interface {
+	GetPolicyIndex(key string) (*authorization.PolicyIndex, error)
+}
+
+// UserAttributesGetter is implemented by the policy getters which also provide the attributes of the users.
+type UserAttributesGetter interface {
+	GetUserAttributes(username string) (map[string]interface{}, error)
+}
+
 // Authorization implements authorization.AuthorizationInterface interface.
 type Authorization struct {
 	getter PolicyGetter
 }
 
+var _ authorization.PolicyFinder = (*Authorization)(nil)
+
 // NewAuthorization create a new Authorization instance.
 func NewAuthorization(getter PolicyGetter) authorization.AuthorizationInterface {
 	return &Authorization{getter}
@@ -60,6 +72,51 @@ func (auth *Authorization) List(username string) ([]*ladon.DefaultPolicy, error)
 	return auth.getter.GetPolicy(username)
 }
 
+// FindRequestCandidates returns the policies under the username which could match the request.
+func (auth *Authorization) FindRequestCandidates(username string, r *ladon.Request) ([]*ladon.DefaultPolicy, error) {
+	idx, err := auth.policyIndex(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx.FindRequestCandidates(r), nil
+}
+
+// FindPoliciesForSubject returns the policies under the username which could match the subject.
+func (auth *Authorization) FindPoliciesForSubject(username, subject string) ([]*ladon.DefaultPolicy, error) {
+	idx, err := auth.policyIndex(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx.FindPoliciesForSubject(subject), nil
+}
+
+// FindPoliciesForResource returns the policies under the username which could match the resource.
+func (auth *Authorization) FindPoliciesForResource(username, resource string) ([]*ladon.DefaultPolicy, error) {
+	idx, err := auth.policyIndex(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return idx.FindPoliciesForResource(resource), nil
+}
+
+// policyIndex returns the index of the policies under the username, it is built from all the policies
+// under the username if the getter keeps no index.
+func (auth *Authorization) policyIndex(username string) (*authorization.PolicyIndex, error) {
+	if indexer, ok := auth.getter.(PolicyIndexGetter); ok {
+		return indexer.GetPolicyIndex(username)
+	}
+
+	policies, err := auth.getter.GetPolicy(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return authorization.NewPolicyIndex(policies), nil
+}
+
 // LogRejectedAccessRequest write rejected subject access to redis.
 func (auth *Authorization) LogRejectedAccessRequest(r *ladon.Request, p ladon.Policies, d ladon.Policies) {
 	var conclusion string
@@ -74,6 +131,7 @@ func (auth *Authorization) LogRejectedAccessRequest(r *ladon.Request, p ladon.Po
 		conclusion = "no policy allowed access"
 	}
 
+	reasonCode, policy := authorization.DenialReason(r, p, d)
 	rstring, pstring, dstring := convertToString(r, p, d)
 	record := analytics.AnalyticsRecord{
 		TimeStamp:  time.Now().Unix(),
@@ -83,6 +141,8 @@ func (auth *Authorization) LogRejectedAccessRequest(r *ladon.Request, p ladon.Po
 		Request:    rstring,
 		Policies:   pstring,
 		Deciders:   dstring,
+		ReasonCode: reasonCode,
+		Policy:     policy,
 	}
 
 	record.SetExpiry(0)
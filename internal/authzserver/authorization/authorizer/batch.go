@@ -0,0 +1,73 @@
+package authorizer
+
+import (
+	"github.com/ory/ladon"
+
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+)
+
+// batchPolicyGetter memoizes the policies and policy indexes fetched for each user, so that the requests
+// of one batch authorization call share one policy fetch per subject.
+type batchPolicyGetter struct {
+	getter   PolicyGetter
+	policies map[string][]*ladon.DefaultPolicy
+	indexes  map[string]*authorization.PolicyIndex
+}
+
+var _ PolicyIndexGetter = (*batchPolicyGetter)(nil)
+
+// NewBatchPolicyGetter wraps getter with a per-call memoization. The returned getter is not safe
+// for concurrent use and should not outlive the call it is created for.
+func NewBatchPolicyGetter(getter PolicyGetter) PolicyGetter {
+	return &batchPolicyGetter{
+		getter:   getter,
+		policies: make(map[string][]*ladon.DefaultPolicy),
+		indexes:  make(map[string]*authorization.PolicyIndex),
+	}
+}
+
+// GetPolicy returns the policies of the given user, fetching them at most once.
+func (b *batchPolicyGetter) GetPolicy(key string) ([]*ladon.DefaultPolicy, error) {
+	if policies, ok := b.policies[key]; ok {
+		return policies, nil
+	}
+
+	policies, err := b.getter.GetPolicy(key)
+	if err != nil {
+		return nil, err
+	}
+
+	b.policies[key] = policies
+
+	return policies, nil
+}
+
+// GetPolicyIndex returns the policy index of the given user, fetching it at most once. The index is
+// built from the fetched policies when the wrapped getter keeps no index.
+func (b *batchPolicyGetter) GetPolicyIndex(key string) (*authorization.PolicyIndex, error) {
+	if idx, ok := b.indexes[key]; ok {
+		return idx, nil
+	}
+
+	var (
+		idx *authorization.PolicyIndex
+		err error
+	)
+
+	if indexer, ok := b.getter.(PolicyIndexGetter); ok {
+		idx, err = indexer.GetPolicyIndex(key)
+	} else {
+		var policies []*ladon.DefaultPolicy
+		if policies, err = b.GetPolicy(key); err == nil {
+			idx = authorization.NewPolicyIndex(policies)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	b.indexes[key] = idx
+
+	return idx, nil
+}
@@ -4,26 +4,34 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/marmotedu/component-base/pkg/core"
 	"github.com/marmotedu/errors"
-	"github.com/nico612/iam-demo/internal/authzserver/authorization"
 	"github.com/nico612/iam-demo/internal/authzserver/authorization/authorizer"
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
 	"github.com/nico612/iam-demo/internal/pkg/code"
 	"github.com/ory/ladon"
 )
 
 // AuthzController create a authorizer handler used to handle authorizer request.
 type AuthzController struct {
-	store authorizer.PolicyGetter
+	store        authorizer.PolicyGetter
+	decisions    authorization.DecisionCache
+	maxBatchSize int
 }
 
 // NewAuthzController creates a authorizer handler.
-func NewAuthzController(store authorizer.PolicyGetter) *AuthzController {
+func NewAuthzController(
+	store authorizer.PolicyGetter,
+	decisions authorization.DecisionCache,
+	maxBatchSize int,
+) *AuthzController {
 	return &AuthzController{
-		store: store,
+		store:        store,
+		decisions:    decisions,
+		maxBatchSize: maxBatchSize,
 	}
 }
 
 // Authorize returns whether a request is allow or deny to access a resource and do some action
-// under specified condition.
+// under specified condition. With `explain=true` query the response explains how the decision is made.
 func (a *AuthzController) Authorize(c *gin.Context) {
 	var r ladon.Request
 	if err := c.ShouldBind(&r); err != nil {
@@ -32,12 +40,14 @@ func (a *AuthzController) Authorize(c *gin.Context) {
 		return
 	}
 
-	auth := authorization.NewAuthorizer(authorizer.NewAuthorization(a.store))
-	if r.Context == nil {
-		r.Context = ladon.Context{}
+	auth := authorization.NewCachedAuthorizer(authorizer.NewAuthorization(a.store), a.decisions)
+	setRequestContext(a.store, c.GetString("username"), &r)
+	if c.Query("explain") == "true" {
+		core.WriteResponse(c, nil, auth.Explain(&r))
+
+		return
 	}
 
-	r.Context["username"] = c.GetString("username")
 	rsp := auth.Authorize(&r)
 
 	core.WriteResponse(c, nil, rsp)
@@ -0,0 +1,79 @@
+package authorize
+
+import (
+	"github.com/gin-gonic/gin"
+	authzv1 "github.com/marmotedu/api/authz/v1"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+
+	"github.com/nico612/iam-demo/internal/authzserver/authorization/authorizer"
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+)
+
+// BatchRequest defines the request body of batch authorization.
+type BatchRequest struct {
+	Requests []*ladon.Request `json:"requests" binding:"required"`
+}
+
+// BatchResponse defines the response of batch authorization, Responses are in the order of the requests.
+type BatchResponse struct {
+	Responses []*authorization.Response `json:"responses"`
+}
+
+// BatchAuthorize authorizes a list of requests in one call. All the requests are evaluated
+// for the authenticated user and share one policy fetch.
+func (a *AuthzController) BatchAuthorize(c *gin.Context) {
+	var r BatchRequest
+	if err := c.ShouldBindJSON(&r); err != nil {
+		core.WriteResponse(c, errors.WithCode(code.ErrBind, err.Error()), nil)
+
+		return
+	}
+
+	if len(r.Requests) > a.maxBatchSize {
+		core.WriteResponse(
+			c,
+			errors.WithCode(code.ErrValidation, "at most %d requests are allowed in one call", a.maxBatchSize),
+			nil,
+		)
+
+		return
+	}
+
+	rsp := BatchResponse{Responses: batchAuthorize(a.store, a.decisions, c.GetString("username"), r.Requests)}
+
+	core.WriteResponse(c, nil, rsp)
+}
+
+// batchAuthorize evaluates the requests for the user with one shared policy fetch,
+// the responses are in the order of the requests.
+func batchAuthorize(
+	store authorizer.PolicyGetter,
+	decisions authorization.DecisionCache,
+	username string,
+	requests []*ladon.Request,
+) []*authorization.Response {
+	auth := authorization.NewCachedAuthorizer(
+		authorizer.NewAuthorization(authorizer.NewBatchPolicyGetter(store)),
+		decisions,
+	)
+	responses := make([]*authorization.Response, 0, len(requests))
+
+	for _, request := range requests {
+		if request == nil {
+			responses = append(responses, &authorization.Response{
+				Response:   authzv1.Response{Denied: true, Reason: "invalid request"},
+				ReasonCode: code.ErrValidation,
+			})
+
+			continue
+		}
+
+		setRequestContext(store, username, request)
+		responses = append(responses, auth.Authorize(request))
+	}
+
+	return responses
+}
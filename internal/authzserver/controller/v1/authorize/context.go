@@ -0,0 +1,28 @@
+package authorize
+
+import (
+	"github.com/ory/ladon"
+
+	"github.com/nico612/iam-demo/internal/authzserver/authorization/authorizer"
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+)
+
+// setRequestContext sets the trusted context of the request: the authenticated username and the attributes
+// of the user. The values given by the caller under the same keys are overwritten.
+func setRequestContext(store authorizer.PolicyGetter, username string, r *ladon.Request) {
+	if r.Context == nil {
+		r.Context = ladon.Context{}
+	}
+
+	r.Context["username"] = username
+	delete(r.Context, authorization.ContextKeyUserAttributes)
+
+	getter, ok := store.(authorizer.UserAttributesGetter)
+	if !ok {
+		return
+	}
+
+	if attributes, err := getter.GetUserAttributes(username); err == nil && attributes != nil {
+		r.Context[authorization.ContextKeyUserAttributes] = attributes
+	}
+}
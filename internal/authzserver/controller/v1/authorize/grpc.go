@@ -0,0 +1,103 @@
+package authorize
+
+import (
+	"context"
+
+	"github.com/ory/ladon"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/nico612/iam-demo/api/proto/authzserver/v1"
+	"github.com/nico612/iam-demo/internal/authzserver/authorization/authorizer"
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+	"github.com/nico612/iam-demo/internal/pkg/middleware/auth"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// AuthzServer implements the authorization grpc service, it shares the authorizer with AuthzController.
+type AuthzServer struct {
+	pb.UnimplementedAuthzServer
+
+	store        authorizer.PolicyGetter
+	decisions    authorization.DecisionCache
+	maxBatchSize int
+}
+
+var _ pb.AuthzServer = (*AuthzServer)(nil)
+
+// NewAuthzServer creates a authorization grpc service.
+func NewAuthzServer(
+	store authorizer.PolicyGetter,
+	decisions authorization.DecisionCache,
+	maxBatchSize int,
+) *AuthzServer {
+	return &AuthzServer{
+		store:        store,
+		decisions:    decisions,
+		maxBatchSize: maxBatchSize,
+	}
+}
+
+// Authorize returns whether a request is allow or deny to access a resource and do some action
+// under specified condition.
+func (a *AuthzServer) Authorize(ctx context.Context, r *pb.AuthorizeRequest) (*pb.AuthorizeResponse, error) {
+	log.L(ctx).Debug("grpc authorize function called.")
+
+	request := toLadonRequest(r)
+	setRequestContext(a.store, auth.UsernameFromContext(ctx), request)
+
+	rsp := authorization.NewCachedAuthorizer(authorizer.NewAuthorization(a.store), a.decisions).Authorize(request)
+
+	return toAuthorizeResponse(rsp), nil
+}
+
+// BatchAuthorize authorizes a list of requests in one call.
+func (a *AuthzServer) BatchAuthorize(
+	ctx context.Context,
+	r *pb.BatchAuthorizeRequest,
+) (*pb.BatchAuthorizeResponse, error) {
+	log.L(ctx).Debug("grpc batch authorize function called.")
+
+	if len(r.Requests) > a.maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d requests are allowed in one call", a.maxBatchSize)
+	}
+
+	requests := make([]*ladon.Request, 0, len(r.Requests))
+	for _, request := range r.Requests {
+		requests = append(requests, toLadonRequest(request))
+	}
+
+	responses := batchAuthorize(a.store, a.decisions, auth.UsernameFromContext(ctx), requests)
+	rsp := &pb.BatchAuthorizeResponse{Responses: make([]*pb.AuthorizeResponse, 0, len(responses))}
+	for _, response := range responses {
+		rsp.Responses = append(rsp.Responses, toAuthorizeResponse(response))
+	}
+
+	return rsp, nil
+}
+
+func toLadonRequest(r *pb.AuthorizeRequest) *ladon.Request {
+	request := &ladon.Request{
+		Resource: r.GetResource(),
+		Action:   r.GetAction(),
+		Subject:  r.GetSubject(),
+		Context:  ladon.Context{},
+	}
+
+	for k, v := range r.GetContext().AsMap() {
+		request.Context[k] = v
+	}
+
+	return request
+}
+
+func toAuthorizeResponse(rsp *authorization.Response) *pb.AuthorizeResponse {
+	return &pb.AuthorizeResponse{
+		Allowed:    rsp.Allowed,
+		Denied:     rsp.Denied,
+		Reason:     rsp.Reason,
+		Error:      rsp.Error,
+		ReasonCode: int32(rsp.ReasonCode),
+		Policy:     rsp.Policy,
+	}
+}
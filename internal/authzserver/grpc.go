@@ -0,0 +1,124 @@
+package authzserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+
+	pb "github.com/nico612/iam-demo/api/proto/authzserver/v1"
+	"github.com/nico612/iam-demo/internal/authzserver/controller/v1/authorize"
+	"github.com/nico612/iam-demo/internal/authzserver/load/cache"
+	"github.com/nico612/iam-demo/internal/pkg/middleware/auth"
+	genericoptions "github.com/nico612/iam-demo/internal/pkg/options"
+	genericapiserver "github.com/nico612/iam-demo/internal/pkg/server"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// grpc 授权服务
+
+type grpcAuthzServer struct {
+	*grpc.Server
+	address string
+}
+
+// newGRPCAuthzServer creates the authorization grpc server, it is served with the same tls certificate
+// and client ca as the HTTPS server.
+func newGRPCAuthzServer(
+	grpcOptions *genericoptions.GRPCOptions,
+	secureServing *genericoptions.SecureServingOptions,
+	authentication *genericoptions.AuthenticationOptions,
+	rateLimitOptions *genericoptions.RateLimitOptions,
+	maxBatchSize int,
+) (*grpcAuthzServer, error) {
+	cert, err := tls.LoadX509KeyPair(secureServing.ServerCert.CertKey.CertFile, secureServing.ServerCert.CertKey.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	tlsConfig, err := genericapiserver.NewClientAuthTLSConfig(
+		secureServing.ClientCert.ClientCA,
+		secureServing.ClientCert.Required,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client ca: %w", err)
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig.Certificates = []tls.Certificate{cert}
+
+	cacheIns, err := cache.GetCacheInsOr(nil)
+	if err != nil || cacheIns == nil {
+		return nil, fmt.Errorf("get nil cache instance")
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.MaxRecvMsgSize(grpcOptions.MaxMsgSize),
+		grpc.Creds(credentials.NewTLS(tlsConfig)),
+		// 先认证再限流，限流需要认证得到的 secret 和用户
+		grpc.ChainUnaryInterceptor(
+			newGRPCAuthInterceptor(authentication.Strategies, &secureServing.ClientCert),
+			rateLimitInterceptor(rateLimitOptions),
+		),
+	)
+
+	pb.RegisterAuthzServer(grpcServer, authorize.NewAuthzServer(cacheIns, cacheIns.DecisionCache(), maxBatchSize))
+
+	reflection.Register(grpcServer)
+
+	return &grpcAuthzServer{
+		Server:  grpcServer,
+		address: net.JoinHostPort(grpcOptions.BindAddress, fmt.Sprintf("%d", grpcOptions.BindPort)),
+	}, nil
+}
+
+// newGRPCAuthInterceptor builds the grpc authentication from the cache and mtls strategies of the chain. The
+// cache strategy authenticates the JWT signed with a secret and passed with the `authorization` metadata, the
+// mtls strategy maps the verified client certificate, the calls without one fall through to the cache strategy.
+func newGRPCAuthInterceptor(
+	names []string,
+	clientCert *genericoptions.ClientCertAuthenticationOptions,
+) grpc.UnaryServerInterceptor {
+	var cacheInterceptor grpc.UnaryServerInterceptor
+	for _, name := range names {
+		if name == auth.StrategyCache {
+			cacheInterceptor = auth.NewCacheStrategy(getSecretFunc()).UnaryServerInterceptor()
+		}
+	}
+
+	for _, name := range names {
+		if name == auth.StrategyMTLS {
+			return newCertAuth(clientCert).UnaryServerInterceptor(cacheInterceptor)
+		}
+	}
+
+	if cacheInterceptor == nil {
+		log.Fatalf("grpc authorization server requires the cache or mtls authentication strategy")
+	}
+
+	return cacheInterceptor
+}
+
+func (s *grpcAuthzServer) Run() {
+	listen, err := net.Listen("tcp", s.address)
+	if err != nil {
+		log.Fatalf("failed to listen: %s", err.Error())
+	}
+
+	go func() {
+		if err = s.Serve(listen); err != nil {
+			log.Fatalf("failed to start grpc server: %s", err.Error())
+		}
+	}()
+
+	log.Infof("start grpc server at %s", s.address)
+}
+
+func (s *grpcAuthzServer) Close() {
+	s.GracefulStop()
+	log.Infof("GRPC server on %s stopped", s.address)
+}
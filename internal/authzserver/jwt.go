@@ -9,12 +9,47 @@ import (
 	"github.com/nico612/iam-demo/internal/authzserver/load/cache"
 	"github.com/nico612/iam-demo/internal/pkg/middleware"
 	"github.com/nico612/iam-demo/internal/pkg/middleware/auth"
+	genericoptions "github.com/nico612/iam-demo/internal/pkg/options"
+	"github.com/nico612/iam-demo/pkg/log"
 )
 
 func newCacheAuth() middleware.AuthStrategy {
 	return auth.NewCacheStrategy(getSecretFunc())
 }
 
+// newCertAuth maps the identity of a client certificate verified against the client ca to a username with the
+// explicit mapping of secure.client-cert.users, iam-authz-server has no user store to look it up.
+func newCertAuth(opts *genericoptions.ClientCertAuthenticationOptions) auth.CertStrategy {
+	return auth.NewCertStrategy(opts.Identity, auth.NewCertUserMapping(opts.UserMapping()))
+}
+
+// newAuthChain builds the authentication chain from the ordered strategy names.
+func newAuthChain(names []string, clientCert *genericoptions.ClientCertAuthenticationOptions) middleware.AuthStrategy {
+	strategies := map[string]func() middleware.AuthStrategy{
+		auth.StrategyCache: newCacheAuth,
+		auth.StrategyMTLS: func() middleware.AuthStrategy {
+			return newCertAuth(clientCert)
+		},
+	}
+
+	authenticators := make([]auth.NamedAuthenticator, 0, len(names))
+	for _, name := range names {
+		newStrategy, ok := strategies[name]
+		if !ok {
+			log.Fatalf("authentication strategy `%s` is not supported by iam-authz-server", name)
+		}
+
+		authenticator, ok := newStrategy().(auth.Authenticator)
+		if !ok {
+			log.Fatalf("authentication strategy `%s` can not take part in an authentication chain", name)
+		}
+
+		authenticators = append(authenticators, auth.NamedAuthenticator{Name: name, Authenticator: authenticator})
+	}
+
+	return auth.NewChainStrategy(authenticators...)
+}
+
 func getSecretFunc() func(string) (auth.Secret, error) {
 	return func(kid string) (auth.Secret, error) {
 		cli, err := cache.GetCacheInsOr(nil)
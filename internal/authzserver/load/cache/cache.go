@@ -4,7 +4,9 @@ import (
 	"github.com/dgraph-io/ristretto"
 	pb "github.com/marmotedu/api/proto/apiserver/v1"
 	"github.com/marmotedu/errors"
+	"github.com/nico612/iam-demo/internal/authzserver/authorization/authorizer"
 	"github.com/nico612/iam-demo/internal/authzserver/store"
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
 	"github.com/ory/ladon"
 	"sync"
 )
@@ -15,8 +17,28 @@ type Cache struct {
 	cli      store.Factory
 	secrets  *ristretto.Cache
 	policies *ristretto.Cache
+
+	// indexes 保存每个用户策略的索引，在重新加载策略时构建
+	indexes map[string]*authorization.PolicyIndex
+
+	// users 保存用户属性，用于策略中的用户属性条件
+	users map[string]map[string]interface{}
+
+	// decisions 缓存授权结果，为 nil 时不缓存
+	decisions *DecisionCache
+
+	// snapshotter 保存本地快照，为 nil 时不保存
+	snapshotter *Snapshotter
+
+	// status 记录加载状态，用于就绪检查
+	status status
 }
 
+var (
+	_ authorizer.PolicyIndexGetter    = (*Cache)(nil)
+	_ authorizer.UserAttributesGetter = (*Cache)(nil)
+)
+
 var (
 	// ErrSecretNotFound defines secret not found error.
 	ErrSecretNotFound = errors.New("secret not found")
@@ -24,6 +46,8 @@ var (
 	ErrPolicyNotFound = errors.New("policy not found")
 )
 
+var emptyPolicyIndex = authorization.NewPolicyIndex(nil)
+
 var (
 	onceCache sync.Once
 	cacheIns  *Cache
@@ -57,6 +81,8 @@ func GetCacheInsOr(cli store.Factory) (*Cache, error) {
 				lock:     new(sync.RWMutex),
 				secrets:  secretCache,
 				policies: policyCache,
+				indexes:  make(map[string]*authorization.PolicyIndex),
+				users:    make(map[string]map[string]interface{}),
 			}
 		})
 	}
@@ -90,33 +116,192 @@ func (c *Cache) GetPolicy(key string) ([]*ladon.DefaultPolicy, error) {
 	return value.([]*ladon.DefaultPolicy), nil
 }
 
-// Reload secrets and policies.
-func (c *Cache) Reload() error {
+// GetPolicyIndex return the index of user's ladon policies for the given user. A user without policies
+// has an empty index, so that the request is denied by ladon as no policy allows it.
+func (c *Cache) GetPolicyIndex(key string) (*authorization.PolicyIndex, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	idx, ok := c.indexes[key]
+	if !ok {
+		return emptyPolicyIndex, nil
+	}
+
+	return idx, nil
+}
+
+// GetUserAttributes return the attributes of the given user, nil if the user has no attributes.
+func (c *Cache) GetUserAttributes(username string) (map[string]interface{}, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.users[username], nil
+}
+
+// SetDecisionCache enables the decision cache, it is invalidated whenever the cache is reloaded.
+func (c *Cache) SetDecisionCache(decisions *DecisionCache) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	// reload secrets
+	c.decisions = decisions
+}
+
+// DecisionCache returns the decision cache, nil if it is not enabled.
+func (c *Cache) DecisionCache() authorization.DecisionCache {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.decisions == nil {
+		return nil
+	}
+
+	return c.decisions
+}
+
+// Revision returns the revision of the store the secrets and policies are loaded from.
+func (c *Cache) Revision() (string, error) {
+	return c.cli.Revision()
+}
+
+// Reload secrets and policies. Everything is fetched before the cache is touched, so that the cache keeps
+// serving the old entries while fetching, and stays unchanged when any fetch fails.
+func (c *Cache) Reload() error {
 	secrets, err := c.cli.Secrets().List()
 	if err != nil {
-		return errors.Wrap(err, "list secrets failed")
-	}
-	c.secrets.Clear()
+		err = errors.Wrap(err, "list secrets failed")
+		c.recordError(err)
 
-	for key, val := range secrets {
-		c.secrets.Set(key, val, 1)
+		return err
 	}
 
-	// reload policies
 	policies, err := c.cli.Policies().List()
 	if err != nil {
-		return errors.Wrap(err, "list policies failed")
+		err = errors.Wrap(err, "list policies failed")
+		c.recordError(err)
+
+		return err
+	}
+
+	// reload user attributes
+	users, err := c.cli.Users().List()
+	if err != nil {
+		err = errors.Wrap(err, "list user attributes failed")
+		c.recordError(err)
+
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// 全部获取成功后再替换缓存，替换期间查询被锁阻塞而不会未命中
+	c.secrets.Clear()
+	for key, val := range secrets {
+		c.secrets.Set(key, val, 1)
 	}
 
 	c.policies.Clear()
+	c.indexes = make(map[string]*authorization.PolicyIndex, len(policies))
 	for key, value := range policies {
 		c.policies.Set(key, value, 1)
+		c.indexes[key] = authorization.NewPolicyIndex(value)
+	}
+
+	c.secrets.Wait()
+	c.policies.Wait()
+
+	c.users = users
+	c.status.secrets = len(secrets)
+
+	if c.decisions != nil {
+		c.decisions.Invalidate()
+	}
+
+	c.saveSnapshot(&snapshotData{Secrets: secrets, Policies: policies, Users: users})
+	c.recordLoad(true, nil)
+
+	return nil
+}
+
+// ReloadSecret refetches the secret of the user with the given secret id, the secret is dropped
+// from the cache when it does not exist any more.
+func (c *Cache) ReloadSecret(username, secretID string) error {
+	secret, err := c.cli.Secrets().Get(username, secretID)
+	if err != nil && !errors.Is(err, store.ErrSecretNotFound) {
+		err = errors.Wrapf(err, "get secret %s failed", secretID)
+		c.recordError(err)
+
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, existed := c.secrets.Get(secretID)
+
+	if secret == nil {
+		c.secrets.Del(secretID)
+		if existed {
+			c.status.secrets--
+		}
+
+		c.recordLoad(false, nil)
+
+		return nil
+	}
+
+	c.secrets.Set(secretID, secret, 1)
+	c.secrets.Wait()
+	if !existed {
+		c.status.secrets++
 	}
 
+	c.recordLoad(false, nil)
+
 	return nil
+}
+
+// ReloadPolicies refetches the policies and attributes of the given user.
+func (c *Cache) ReloadPolicies(username string) error {
+	policies, err := c.cli.Policies().ListByUser(username)
+	if err != nil {
+		err = errors.Wrapf(err, "list policies of user %s failed", username)
+		c.recordError(err)
+
+		return err
+	}
+
+	attributes, err := c.cli.Users().Get(username)
+	if err != nil {
+		err = errors.Wrapf(err, "get attributes of user %s failed", username)
+		c.recordError(err)
+
+		return err
+	}
 
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(policies) == 0 {
+		c.policies.Del(username)
+		delete(c.indexes, username)
+	} else {
+		c.policies.Set(username, policies, 1)
+		c.policies.Wait()
+		c.indexes[username] = authorization.NewPolicyIndex(policies)
+	}
+
+	if len(attributes) == 0 {
+		delete(c.users, username)
+	} else {
+		c.users[username] = attributes
+	}
+
+	if c.decisions != nil {
+		c.decisions.Invalidate()
+	}
+
+	c.recordLoad(false, nil)
+
+	return nil
 }
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// CacheOptions contains configuration items related to the secret and policy cache.
+type CacheOptions struct {
+	MaxStaleness time.Duration `json:"max-staleness" mapstructure:"max-staleness"` // 重新加载持续失败超过该时间后服务变为未就绪，0 表示不检查
+	ResyncPeriod time.Duration `json:"resync-period" mapstructure:"resync-period"` // 定期全量同步的间隔，0 表示不同步
+	SnapshotPath string        `json:"snapshot-path" mapstructure:"snapshot-path"` // 本地快照文件路径，为空时不保存快照
+	SnapshotKey  string        `json:"snapshot-key"  mapstructure:"snapshot-key"`  // 加密快照使用的密钥
+}
+
+// NewCacheOptions creates a CacheOptions object with default parameters.
+func NewCacheOptions() *CacheOptions {
+	return &CacheOptions{
+		MaxStaleness: 0,
+		ResyncPeriod: 5 * time.Minute,
+		SnapshotPath: "",
+		SnapshotKey:  "",
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *CacheOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	errors := []error{}
+
+	if o.MaxStaleness < 0 {
+		errors = append(errors, fmt.Errorf("--cache.max-staleness %v must not be negative", o.MaxStaleness))
+	}
+
+	if o.ResyncPeriod < 0 {
+		errors = append(errors, fmt.Errorf("--cache.resync-period %v must not be negative", o.ResyncPeriod))
+	}
+
+	if o.SnapshotPath != "" && o.SnapshotKey == "" {
+		errors = append(errors, fmt.Errorf("--cache.snapshot-key is required when --cache.snapshot-path is set"))
+	}
+
+	return errors
+}
+
+// AddFlags adds flags related to the cache for a specific server to the
+// specified FlagSet.
+func (o *CacheOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.DurationVar(&o.MaxStaleness, "cache.max-staleness", o.MaxStaleness, ""+
+		"If set, the server reports unready on /readyz when reloading the secrets and policies keeps failing "+
+		"and the cache has not been refreshed for longer than this duration. Set to zero to disable the check.")
+
+	fs.DurationVar(&o.ResyncPeriod, "cache.resync-period", o.ResyncPeriod, ""+
+		"How often all the secrets and policies are resynced, independent of the change notifications. "+
+		"The resync is skipped when the revision of the source has not changed. Set to zero to disable the resync.")
+
+	fs.StringVar(&o.SnapshotPath, "cache.snapshot-path", o.SnapshotPath, ""+
+		"If set, an encrypted snapshot of the secrets and policies is written to the file after every full reload. "+
+		"On startup the snapshot is served in degraded mode until the secrets and policies can be reloaded.")
+
+	fs.StringVar(&o.SnapshotKey, "cache.snapshot-key", o.SnapshotKey, ""+
+		"The key used to encrypt the snapshot, required when --cache.snapshot-path is set.")
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+)
+
+// 授权结果缓存的命中率指标，通过 /metrics 暴露.
+var (
+	decisionCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "iam_authz",
+		Name:      "decision_cache_hits_total",
+		Help:      "The number of authorization decisions served from the decision cache.",
+	})
+	decisionCacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "iam_authz",
+		Name:      "decision_cache_misses_total",
+		Help:      "The number of authorization decisions not found in the decision cache.",
+	})
+	decisionCacheInvalidations = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "iam_authz",
+		Name:      "decision_cache_invalidations_total",
+		Help:      "The number of times the decision cache is invalidated by a reload.",
+	})
+)
+
+// DecisionCache is a bounded cache of authorization decisions with a short ttl.
+// All the decisions are invalidated when the secrets and policies are reloaded.
+type DecisionCache struct {
+	decisions  *ristretto.Cache
+	ttl        time.Duration
+	generation uint64
+}
+
+var _ authorization.DecisionCache = (*DecisionCache)(nil)
+
+// NewDecisionCache creates a decision cache holding at most size decisions.
+func NewDecisionCache(size int64, ttl time.Duration) (*DecisionCache, error) {
+	decisions, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: size * 10, // 官方建议为最大条目数的 10 倍
+		MaxCost:     size,
+		BufferItems: 64,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DecisionCache{decisions: decisions, ttl: ttl}, nil
+}
+
+// Generation returns the current generation of the decisions.
+func (d *DecisionCache) Generation() uint64 {
+	return atomic.LoadUint64(&d.generation)
+}
+
+// GetDecision returns the decision cached for the request key in the given generation.
+func (d *DecisionCache) GetDecision(generation uint64, key string) (*authorization.Decision, bool) {
+	value, ok := d.decisions.Get(decisionKey(generation, key))
+	if !ok {
+		decisionCacheMisses.Inc()
+
+		return nil, false
+	}
+
+	decisionCacheHits.Inc()
+
+	return value.(*authorization.Decision), true
+}
+
+// SetDecision caches the decision computed in the given generation,
+// decisions of an outdated generation are dropped.
+func (d *DecisionCache) SetDecision(generation uint64, key string, decision *authorization.Decision) {
+	if generation != d.Generation() {
+		return
+	}
+
+	d.decisions.SetWithTTL(decisionKey(generation, key), decision, 1, d.ttl)
+}
+
+// Invalidate drops all the cached decisions.
+func (d *DecisionCache) Invalidate() {
+	// 先切换 generation，正在计算的旧 generation 的结果不会再被读取
+	atomic.AddUint64(&d.generation, 1)
+	d.decisions.Clear()
+	decisionCacheInvalidations.Inc()
+}
+
+func decisionKey(generation uint64, key string) string {
+	return fmt.Sprintf("%d-%s", generation, key)
+}
@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// snapshot：每次全量加载成功后，将 secrets、policies 和用户属性加密写入本地文件。
+// 启动时先加载快照，在无法连接数据源时以降级模式提供服务，直到全量加载成功.
+
+// snapshotVersion is the version of the snapshot file format.
+const snapshotVersion = 1
+
+// cacheDegraded reports whether the cache is served from a snapshot, it is exposed by /metrics.
+var cacheDegraded = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "iam_authz",
+	Name:      "cache_degraded",
+	Help:      "Set to 1 while the secrets and policies are served from the local snapshot.",
+})
+
+// snapshotData is the content of a snapshot.
+type snapshotData struct {
+	Secrets  map[string]*pb.SecretInfo         `json:"secrets"`
+	Policies map[string][]*ladon.DefaultPolicy `json:"policies"`
+	Users    map[string]map[string]interface{} `json:"users"`
+}
+
+// snapshotFile is the format of the snapshot file. Data is the snapshotData encrypted with AES-GCM,
+// and Checksum is the sha256 checksum of the plain data.
+type snapshotFile struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Checksum  string    `json:"checksum"`
+	Nonce     []byte    `json:"nonce"`
+	Data      []byte    `json:"data"`
+}
+
+// Snapshotter reads and writes the encrypted snapshot file of the cache.
+type Snapshotter struct {
+	path string
+	aead cipher.AEAD
+}
+
+// NewSnapshotter creates a snapshotter which stores the snapshot at path, encrypted with a key derived from key.
+func NewSnapshotter(path, key string) (*Snapshotter, error) {
+	if key == "" {
+		return nil, fmt.Errorf("snapshot key is required")
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "create snapshot cipher failed")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "create snapshot cipher failed")
+	}
+
+	return &Snapshotter{path: path, aead: aead}, nil
+}
+
+// save encrypts and writes the snapshot, the file is replaced atomically.
+func (s *Snapshotter) save(data *snapshotData) error {
+	plain, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "encode snapshot failed")
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return errors.Wrap(err, "generate snapshot nonce failed")
+	}
+
+	checksum := sha256.Sum256(plain)
+	content, err := json.Marshal(&snapshotFile{
+		Version:   snapshotVersion,
+		CreatedAt: time.Now(),
+		Checksum:  hex.EncodeToString(checksum[:]),
+		Nonce:     nonce,
+		Data:      s.aead.Seal(nil, nonce, plain, nil),
+	})
+	if err != nil {
+		return errors.Wrap(err, "encode snapshot failed")
+	}
+
+	// 先写临时文件再重命名，避免进程退出时留下不完整的快照
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "create snapshot file failed")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+
+		return errors.Wrap(err, "write snapshot file failed")
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "write snapshot file failed")
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return errors.Wrap(err, "replace snapshot file failed")
+	}
+
+	return nil
+}
+
+// load reads, decrypts and verifies the snapshot, it returns the snapshot data and the time it was created.
+func (s *Snapshotter) load() (*snapshotData, time.Time, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "read snapshot file failed")
+	}
+
+	var file snapshotFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "decode snapshot file failed")
+	}
+
+	if file.Version != snapshotVersion {
+		return nil, time.Time{}, fmt.Errorf("unsupported snapshot version %d", file.Version)
+	}
+
+	if len(file.Nonce) != s.aead.NonceSize() {
+		return nil, time.Time{}, fmt.Errorf("invalid snapshot nonce")
+	}
+
+	plain, err := s.aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "decrypt snapshot failed")
+	}
+
+	if checksum := sha256.Sum256(plain); hex.EncodeToString(checksum[:]) != file.Checksum {
+		return nil, time.Time{}, fmt.Errorf("snapshot checksum mismatch")
+	}
+
+	var data snapshotData
+	if err := json.Unmarshal(plain, &data); err != nil {
+		return nil, time.Time{}, errors.Wrap(err, "decode snapshot failed")
+	}
+
+	return &data, file.CreatedAt, nil
+}
+
+// SetSnapshotter enables the snapshot, it is written after every successful full reload.
+func (c *Cache) SetSnapshotter(snapshotter *Snapshotter) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.snapshotter = snapshotter
+}
+
+// LoadSnapshot loads the secrets and policies from the snapshot. The cache is degraded until the
+// next successful full reload.
+func (c *Cache) LoadSnapshot() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.snapshotter == nil {
+		return fmt.Errorf("snapshot is not enabled")
+	}
+
+	data, createdAt, err := c.snapshotter.load()
+	if err != nil {
+		return err
+	}
+
+	c.secrets.Clear()
+	for key, val := range data.Secrets {
+		c.secrets.Set(key, val, 1)
+	}
+
+	c.policies.Clear()
+	c.indexes = make(map[string]*authorization.PolicyIndex, len(data.Policies))
+	for key, value := range data.Policies {
+		c.policies.Set(key, value, 1)
+		c.indexes[key] = authorization.NewPolicyIndex(value)
+	}
+
+	c.secrets.Wait()
+	c.policies.Wait()
+
+	c.users = data.Users
+	if c.users == nil {
+		c.users = make(map[string]map[string]interface{})
+	}
+
+	if c.decisions != nil {
+		c.decisions.Invalidate()
+	}
+
+	// 快照的数据和快照一样旧
+	c.status.secrets = len(data.Secrets)
+	c.status.refreshedAt = createdAt
+	c.status.degraded = true
+	cacheDegraded.Set(1)
+
+	log.Warnf("serving secrets and policies from the snapshot created at %s", createdAt.Format(time.RFC3339))
+
+	return nil
+}
+
+// saveSnapshot writes the snapshot if it is enabled, failures are only logged. It must be called with the lock held.
+func (c *Cache) saveSnapshot(data *snapshotData) {
+	if c.snapshotter == nil {
+		return
+	}
+
+	if err := c.snapshotter.save(data); err != nil {
+		log.Errorf("failed to save cache snapshot: %s", err.Error())
+	}
+}
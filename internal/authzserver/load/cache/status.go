@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// Status describes the state of the cache, it is reported by the readiness check.
+type Status struct {
+	// Loaded is true once a full load of the secrets and policies has succeeded.
+	Loaded bool `json:"loaded"`
+	// LoadedAt is the time of the last successful full load.
+	LoadedAt time.Time `json:"loadedAt"`
+	// RefreshedAt is the time of the last successful full or incremental load.
+	RefreshedAt time.Time `json:"refreshedAt"`
+	// Age is the time since RefreshedAt, zero until the cache is loaded.
+	Age time.Duration `json:"-"`
+	// Degraded is true while the cache is served from the snapshot, until the next successful full load.
+	Degraded bool `json:"degraded"`
+
+	Secrets  int `json:"secrets"`
+	Users    int `json:"users"`
+	Policies int `json:"policies"`
+
+	// LastError is the error of the last load, it is cleared by the next successful load.
+	LastError   string    `json:"lastError,omitempty"`
+	LastErrorAt time.Time `json:"lastErrorAt,omitempty"`
+}
+
+// status is the load state kept by the cache, it is protected by the cache lock.
+type status struct {
+	loadedAt    time.Time
+	refreshedAt time.Time
+	secrets     int
+	lastError   error
+	lastErrorAt time.Time
+	degraded    bool
+}
+
+// Status returns the current state of the cache.
+func (c *Cache) Status() Status {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	s := Status{
+		Loaded:      !c.status.loadedAt.IsZero(),
+		LoadedAt:    c.status.loadedAt,
+		RefreshedAt: c.status.refreshedAt,
+		Degraded:    c.status.degraded,
+		Secrets:     c.status.secrets,
+		Users:       len(c.indexes),
+	}
+
+	for _, idx := range c.indexes {
+		s.Policies += len(idx.Policies())
+	}
+
+	if s.Loaded || s.Degraded {
+		s.Age = time.Since(s.RefreshedAt)
+	}
+
+	if c.status.lastError != nil {
+		s.LastError = c.status.lastError.Error()
+		s.LastErrorAt = c.status.lastErrorAt
+	}
+
+	return s
+}
+
+// recordLoad records the result of a load, full is true for a full load. It must be called with the lock held.
+func (c *Cache) recordLoad(full bool, err error) {
+	now := time.Now()
+	if err != nil {
+		c.status.lastError, c.status.lastErrorAt = err, now
+
+		return
+	}
+
+	c.status.lastError = nil
+	c.status.refreshedAt = now
+	if full {
+		c.status.loadedAt = now
+		c.status.degraded = false
+		cacheDegraded.Set(0)
+	}
+}
+
+// recordError records a failed load, it acquires the lock.
+func (c *Cache) recordError(err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.recordLoad(false, err)
+}
+
+// Ready reports whether the cache can serve requests, the reason is set when it is not ready.
+// The cache is ready once the first full load succeeds or the snapshot is loaded, and becomes unready
+// again when maxStaleness is positive, the last load failed and the cache is older than maxStaleness.
+func (s Status) Ready(maxStaleness time.Duration) (bool, string) {
+	if !s.Loaded && !s.Degraded {
+		return false, "secrets and policies have not been loaded"
+	}
+
+	if maxStaleness > 0 && s.LastError != "" && s.Age > maxStaleness {
+		return false, fmt.Sprintf("cache is stale for %s: %s", s.Age.Truncate(time.Second), s.LastError)
+	}
+
+	return true, ""
+}
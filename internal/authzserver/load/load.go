@@ -3,53 +3,110 @@ package load
 import (
 	"context"
 	"github.com/marmotedu/errors"
+	"github.com/nico612/iam-demo/internal/pkg/notification"
 	"github.com/nico612/iam-demo/pkg/log"
 	"github.com/nico612/iam-demo/pkg/storage"
+	"math/rand"
 	"sync"
 	"time"
 )
 
+// Loader reloads the cached secrets and policies. Reload reloads all of them, while ReloadSecret and
+// ReloadPolicies only refetch the entries affected by a notification. Revision returns the current
+// revision of the source, it is used by the periodic resync to skip reloads when nothing has changed.
 type Loader interface {
 	Reload() error
+	ReloadSecret(username, secretID string) error
+	ReloadPolicies(username string) error
+	Revision() (string, error)
+}
+
+// delta describes the entries affected by a notification.
+type delta struct {
+	command  notification.NotificationCommand
+	username string
+	secretID string
+}
+
+// reloadRequest is a queued reload, delta is nil when all the secrets and policies should be reloaded.
+type reloadRequest struct {
+	delta    *delta
+	callback func()
 }
 
 type Load struct {
-	ctx    context.Context
-	lock   *sync.RWMutex
-	loader Loader
+	ctx      context.Context
+	lock     *sync.RWMutex
+	loader   Loader
+	verifier *notification.Verifier
+	// revision 是最近一次全量加载成功时数据源的版本，为空表示未知
+	revision string
 }
 
-// NewLoader return a loader with a loader implement.
-func NewLoader(ctx context.Context, loader Loader) *Load {
+// NewLoader return a loader with a loader implement, notifications are verified by the verifier.
+func NewLoader(ctx context.Context, loader Loader, verifier *notification.Verifier) *Load {
 	return &Load{
-		ctx:    ctx,
-		lock:   new(sync.RWMutex),
-		loader: loader,
+		ctx:      ctx,
+		lock:     new(sync.RWMutex),
+		loader:   loader,
+		verifier: verifier,
 	}
 
 }
 
 // Start a loop service.
 func (l *Load) Start() {
-	go startPubSubLoop()   // 订阅 redis 消息，并写入刷新队列
+	go l.startPubSubLoop() // 订阅 redis 消息，并写入刷新队列
 	go l.reloadQueueLoop() // 读取刷新队列
 
 	// 1s is the minimum amount of time between hot reloads. The
 	// interval counts from the start of one reload to the next.
 	go l.reloadLoop()
 
+	l.DoReload()
+}
+
+// StartResync starts a loop which periodically reloads all the secrets and policies, so that the cache
+// recovers from lost notifications. Each period is extended by a random jitter of up to 10%, and the reload
+// is skipped when the revision of the source has not changed since the last full load.
+func (l *Load) StartResync(period time.Duration) {
+	if period <= 0 {
+		return
+	}
+
+	go func() {
+		for {
+			// 加入随机抖动，避免多个实例同时全量加载
+			wait := period + time.Duration(rand.Int63n(int64(period/10)+1))
+
+			select {
+			case <-l.ctx.Done():
+				return
+			case <-time.After(wait):
+				l.resync()
+			}
+		}
+	}()
+}
+
+// QueueReload queues a reload of all the secrets and policies, it is performed on the next reload cycle.
+func (l *Load) QueueReload() {
+	select {
+	case <-l.ctx.Done():
+	case reloadQueue <- reloadRequest{}:
+	}
 }
 
 // 订阅 redis 消息
-func startPubSubLoop() {
+func (l *Load) startPubSubLoop() {
 	cacheStore := storage.RedisCluster{}
 	cacheStore.Connect()
 
 	// On message, Synchronize
 	for {
 		// 订阅 redis 通道, 并将回调的消息 写入 reloadQueue 队列中
-		err := cacheStore.StartPubSubHandler(RedisPubSubChannel, func(v interface{}) {
-			handleRedisEvent(v, nil, nil)
+		err := cacheStore.StartPubSubHandler(notification.RedisPubSubChannel, func(v interface{}) {
+			handleRedisEvent(v, l.verifier, nil, nil)
 		})
 
 		if err != nil {
@@ -65,7 +122,7 @@ func startPubSubLoop() {
 
 // shouldReload returns true if we should perform any reload. Reloads happens if
 // we have reload callback queued.
-func shouldReload() ([]func(), bool) {
+func shouldReload() ([]reloadRequest, bool) {
 	requeueLock.Lock()
 	defer requeueLock.Unlock()
 
@@ -74,7 +131,7 @@ func shouldReload() ([]func(), bool) {
 	}
 
 	n := requeue
-	requeue = []func(){}
+	requeue = []reloadRequest{}
 
 	return n, true
 }
@@ -89,17 +146,17 @@ func (l *Load) reloadLoop(complete ...func()) {
 		// startup sequence. We expect to start checking on the first tick after the
 		// gateway is up and running.
 		case <-ticker.C:
-			cb, ok := shouldReload()
+			requests, ok := shouldReload()
 			if !ok {
 				continue
 			}
 			start := time.Now()
-			l.DoReload()
-			for _, c := range cb {
+			l.reload(requests)
+			for _, r := range requests {
 				// most of the callbacks are nil, we don't want to execute nil functions to
 				// avoid panics.
-				if c != nil {
-					c() // 处理 requeue 中的回调函数
+				if r.callback != nil {
+					r.callback() // 处理 requeue 中的回调函数
 				}
 			}
 			if len(complete) != 0 {
@@ -112,22 +169,22 @@ func (l *Load) reloadLoop(complete ...func()) {
 
 // reloadQueue used to queue a reload. It's not
 // buffered, as reloadQueueLoop should pick these up immediately.
-var reloadQueue = make(chan func())
+var reloadQueue = make(chan reloadRequest)
 
 var requeueLock sync.Mutex
 
-// This is a list of callbacks to execute on the next reload. It is protected by
+// This is a list of reloads to perform on the next reload cycle. It is protected by
 // requeueLock for concurrent use.
-var requeue []func()
+var requeue []reloadRequest
 
 func (l *Load) reloadQueueLoop(cb ...func()) {
 	for {
 		select {
 		case <-l.ctx.Done():
 			return
-		case fn := <-reloadQueue: // 从刷新队列中读取数据
+		case r := <-reloadQueue: // 从刷新队列中读取数据
 			requeueLock.Lock()
-			requeue = append(requeue, fn) // 储存requeue函数
+			requeue = append(requeue, r) // 储存 reload 请求
 			requeueLock.Unlock()
 			log.Info("Reload queued")
 			if len(cb) != 0 {
@@ -137,15 +194,104 @@ func (l *Load) reloadQueueLoop(cb ...func()) {
 	}
 }
 
+// reload refetches the entries affected by the queued requests. It falls back to a full reload
+// when any request has no delta or any delta fails.
+func (l *Load) reload(requests []reloadRequest) {
+	deltas := make(map[delta]struct{}, len(requests))
+	for _, r := range requests {
+		if r.delta == nil {
+			l.DoReload()
+
+			return
+		}
+
+		deltas[*r.delta] = struct{}{}
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for d := range deltas {
+		var err error
+
+		switch d.command {
+		case notification.NoticeSecretChanged:
+			err = l.loader.ReloadSecret(d.username, d.secretID)
+		case notification.NoticePolicyChanged:
+			err = l.loader.ReloadPolicies(d.username)
+		}
+
+		if err != nil {
+			log.Warnf("failed to refetch changed entries of user %s, reload all: %s", d.username, err.Error())
+
+			l.reloadAll()
+
+			return
+		}
+	}
+
+	log.Debugf("refetched %d changed entries", len(deltas))
+}
+
 // DoReload reload secrets and policies.
 func (l *Load) DoReload() {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
+	l.reloadAll()
+}
+
+// resync reloads all the secrets and policies when the revision of the source has changed.
+// The revision also changes after incremental reloads, so the next resync after a notification
+// performs one extra full reload.
+func (l *Load) resync() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	revision, err := l.loader.Revision()
+	if err != nil {
+		log.Warnf("resync: failed to get revision, reload all: %s", err.Error())
+
+		revision = ""
+	} else if revision == l.revision {
+		log.Debugf("resync: revision %s not changed", revision)
+
+		return
+	}
+
+	if l.reloadRevision(revision) {
+		log.Infof("resync: reloaded secrets and policies, revision %s", revision)
+	}
+}
+
+// reloadAll reloads all the secrets and policies and records the revision they are loaded from.
+// It must be called with the lock held.
+func (l *Load) reloadAll() {
+	// 先获取版本再加载，加载期间发生的修改会在下次同步时被发现
+	revision, err := l.loader.Revision()
+	if err != nil {
+		log.Warnf("failed to get revision: %s", err.Error())
+
+		revision = ""
+	}
+
+	l.reloadRevision(revision)
+}
+
+// reloadRevision reloads all the secrets and policies, and records revision as the revision of the cache
+// when the reload succeeds. It must be called with the lock held.
+func (l *Load) reloadRevision(revision string) bool {
 	// 刷新缓存
 	if err := l.loader.Reload(); err != nil {
 		log.Errorf("faild to refresh target storage: %s", err.Error())
+
+		l.revision = ""
+
+		return false
 	}
 
+	l.revision = revision
 	log.Debug("refresh target storage succ")
+
+	return true
 }
@@ -1,61 +1,64 @@
 package load
 
 import (
-	"crypto"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+
 	"github.com/go-redis/redis/v7"
 	"github.com/marmotedu/errors"
-	"github.com/nico612/iam-demo/pkg/log"
-	"github.com/nico612/iam-demo/pkg/storage"
-)
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 
-// NotificationCommand defines a new notification type.
-type NotificationCommand string
-
-// Define Redis pub/sub events.
-const (
-	RedisPubSubChannel                      = "iam.cluster.notifications"
-	NoticePolicyChanged NotificationCommand = "PolicyChanged"
-	NoticeSecretChanged NotificationCommand = "SecretChanged"
+	"github.com/nico612/iam-demo/internal/pkg/notification"
+	"github.com/nico612/iam-demo/pkg/log"
 )
 
-// Notification is a type that encodes a message published to a pub sub channel (shared between implementations).
-type Notification struct {
-	Command       NotificationCommand `json:"command"`
-	Payload       string              `json:"payload"`
-	Signature     string              `json:"signature"`
-	SignatureAlgo crypto.Hash         `json:"algorithm"`
-}
-
-// Sign Notification with SHA256 algorithm.
-func (n *Notification) Sign() {
-	n.SignatureAlgo = crypto.SHA256
-	hash := sha256.Sum256([]byte(string(n.Command) + n.Payload))
-	n.Signature = hex.EncodeToString(hash[:])
+var notificationsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "iam_authz",
+	Name:      "notifications_rejected_total",
+	Help:      "The number of cluster notifications rejected by verification, partitioned by reason.",
+}, []string{"reason"})
+
+// rejectedReason returns the metric label of a verification error.
+func rejectedReason(err error) string {
+	switch {
+	case errors.Is(err, notification.ErrNotificationAlgorithm):
+		return "algorithm"
+	case errors.Is(err, notification.ErrNotificationExpired):
+		return "expired"
+	case errors.Is(err, notification.ErrNotificationReplayed):
+		return "replayed"
+	default:
+		return "signature"
+	}
 }
 
-// 处理 redis 事件
-func handleRedisEvent(v interface{}, handled func(NotificationCommand), reloaded func()) {
+// 处理 redis 事件，未通过校验的通知直接丢弃
+func handleRedisEvent(v interface{}, verifier *notification.Verifier, handled func(notification.NotificationCommand), reloaded func()) {
 	message, ok := v.(*redis.Message)
 	if !ok {
 		return
 	}
 
-	notif := Notification{}
+	notif := notification.Notification{}
 	if err := json.Unmarshal([]byte(message.Payload), &notif); err != nil {
 		log.Errorf("Unmarshalling message body failed, malformed: ", err)
 
 		return
 	}
 
+	if err := verifier.Verify(&notif); err != nil {
+		notificationsRejected.WithLabelValues(rejectedReason(err)).Inc()
+		log.Warnw("reject redis message", "command", notif.Command, "error", err.Error())
+
+		return
+	}
+
 	log.Infow("receive redis message", "command", notif.Command, "payload", message.Payload)
 
 	switch notif.Command {
-	case NoticePolicyChanged, NoticeSecretChanged:
+	case notification.NoticePolicyChanged, notification.NoticeSecretChanged:
 		log.Infof("Reloading secrets and polices")
-		reloadQueue <- reloaded // 收到通知写入 reloaded 事件
+		reloadQueue <- reloadRequest{delta: notificationDelta(&notif), callback: reloaded} // 收到通知写入 reloaded 事件
 	default:
 		log.Warnf("Unknown notification command: %q", notif.Command)
 		return
@@ -67,35 +70,20 @@ func handleRedisEvent(v interface{}, handled func(NotificationCommand), reloaded
 	}
 }
 
-// RedisNotifier will use redis pub/sub channels to send notifications.
-type RedisNotifier struct {
-	store   *storage.RedisCluster
-	channel string
-}
-
-// Notify will send a notification to a channel.
-func (r *RedisNotifier) Notify(notify interface{}) bool {
-	if n, ok := notify.(Notification); ok {
-		n.Sign() // 签名消息
-		notify = n
+// notificationDelta returns the entries to refetch for the notification, nil if all of them should be reloaded.
+func notificationDelta(n *notification.Notification) *delta {
+	if n.Payload == "" {
+		return nil
 	}
 
-	toSend, err := json.Marshal(notify)
-	if err != nil {
-		log.Errorf("Problem marshaling notification: %s", err.Error())
-
-		return false
+	var payload notification.NotificationPayload
+	if err := json.Unmarshal([]byte(n.Payload), &payload); err != nil || payload.Username == "" {
+		return nil
 	}
 
-	log.Debugf("Sending notification: %v", notify)
-
-	// 发送消息到指定的 redis 通道中
-	if err := r.store.Publish(r.channel, string(toSend)); err != nil {
-		if !errors.Is(err, storage.ErrRedisIsDown) {
-			log.Errorf("Could not send notification: %s", err.Error())
-		}
-		return false
+	if n.Command == notification.NoticeSecretChanged && payload.SecretID == "" {
+		return nil
 	}
 
-	return true
+	return &delta{command: n.Command, username: payload.Username, secretID: payload.SecretID}
 }
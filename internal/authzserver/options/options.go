@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	cliflag "github.com/marmotedu/component-base/pkg/cli/flag"
 	"github.com/nico612/iam-demo/internal/authzserver/analytics"
+	"github.com/nico612/iam-demo/internal/authzserver/authorization"
+	"github.com/nico612/iam-demo/internal/authzserver/load/cache"
 	genericoptions "github.com/nico612/iam-demo/internal/pkg/options"
 	"github.com/nico612/iam-demo/internal/pkg/server"
 	"github.com/nico612/iam-demo/pkg/log"
@@ -11,29 +13,51 @@ import (
 
 // Options runs a authzserver.
 type Options struct {
-	RPCServer               string                                 `json:"rpcserver"      mapstructure:"rpcserver"`
-	ClientCA                string                                 `json:"client-ca-file" mapstructure:"client-ca-file"`
-	GenericServerRunOptions *genericoptions.ServerRunOptions       `json:"server"         mapstructure:"server"`
-	InsecureServing         *genericoptions.InsecureServingOptions `json:"insecure"       mapstructure:"insecure"`
-	SecureServing           *genericoptions.SecureServingOptions   `json:"secure"         mapstructure:"secure"`
-	RedisOptions            *genericoptions.RedisOptions           `json:"redis"          mapstructure:"redis"`
-	FeatureOptions          *genericoptions.FeatureOptions         `json:"feature"        mapstructure:"feature"`
-	Log                     *log.Options                           `json:"log"            mapstructure:"log"`
-	AnalyticsOptions        *analytics.AnalyticsOptions            `json:"analytics"      mapstructure:"analytics"`
+	RPCServer               string                                 `json:"rpcserver"        mapstructure:"rpcserver"`
+	DataDir                 string                                 `json:"data-dir"         mapstructure:"data-dir"`
+	ClientCA                string                                 `json:"client-ca-file"   mapstructure:"client-ca-file"`
+	ClientCert              string                                 `json:"client-cert-file" mapstructure:"client-cert-file"`
+	ClientKey               string                                 `json:"client-key-file"  mapstructure:"client-key-file"`
+	GenericServerRunOptions *genericoptions.ServerRunOptions       `json:"server"           mapstructure:"server"`
+	InsecureServing         *genericoptions.InsecureServingOptions `json:"insecure"         mapstructure:"insecure"`
+	SecureServing           *genericoptions.SecureServingOptions   `json:"secure"           mapstructure:"secure"`
+	GRPCOptions             *genericoptions.GRPCOptions            `json:"grpc"             mapstructure:"grpc"`
+	RedisOptions            *genericoptions.RedisOptions           `json:"redis"            mapstructure:"redis"`
+	FeatureOptions          *genericoptions.FeatureOptions         `json:"feature"          mapstructure:"feature"`
+	Log                     *log.Options                           `json:"log"              mapstructure:"log"`
+	AnalyticsOptions        *analytics.AnalyticsOptions            `json:"analytics"        mapstructure:"analytics"`
+	Authentication          *genericoptions.AuthenticationOptions  `json:"authentication"   mapstructure:"authentication"`
+	AuthorizationOptions    *authorization.AuthorizationOptions    `json:"authorization"    mapstructure:"authorization"`
+	CacheOptions            *cache.CacheOptions                    `json:"cache"            mapstructure:"cache"`
+	NotificationOptions     *genericoptions.NotificationOptions    `json:"notification"     mapstructure:"notification"`
+	RateLimitOptions        *genericoptions.RateLimitOptions       `json:"rate-limit"       mapstructure:"rate-limit"`
 }
 
 // NewOptions creates a new Options object with default parameters.
 func NewOptions() *Options {
+	// iam-authz-server 的 gRPC 授权服务和 iam-apiserver 的 gRPC 服务可能部署在同一台机器上，使用不同的默认端口
+	grpcOptions := genericoptions.NewGRPCOptions()
+	grpcOptions.BindPort = 9091
+
 	o := Options{
 		RPCServer:               "127.0.0.1:8081",
+		DataDir:                 "",
 		ClientCA:                "",
+		ClientCert:              "",
+		ClientKey:               "",
 		GenericServerRunOptions: genericoptions.NewServerRunOptions(),
 		InsecureServing:         genericoptions.NewInsecureServingOptions(),
 		SecureServing:           genericoptions.NewSecureServingOptions(),
+		GRPCOptions:             grpcOptions,
 		RedisOptions:            genericoptions.NewRedisOptions(),
 		FeatureOptions:          genericoptions.NewFeatureOptions(),
 		Log:                     log.NewOptions(),
 		AnalyticsOptions:        analytics.NewAnalyticsOptions(),
+		Authentication:          genericoptions.NewAuthenticationOptions("cache"),
+		AuthorizationOptions:    authorization.NewAuthorizationOptions(),
+		CacheOptions:            cache.NewCacheOptions(),
+		NotificationOptions:     genericoptions.NewNotificationOptions(),
+		RateLimitOptions:        genericoptions.NewRateLimitOptions(),
 	}
 
 	return &o
@@ -49,10 +73,16 @@ func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
 
 	o.GenericServerRunOptions.AddFlags(fss.FlagSet("generic"))
 	o.AnalyticsOptions.AddFlags(fss.FlagSet("analytics"))
+	o.AuthorizationOptions.AddFlags(fss.FlagSet("authorization"))
+	o.CacheOptions.AddFlags(fss.FlagSet("cache"))
+	o.RateLimitOptions.AddFlags(fss.FlagSet("rate limit"))
 	o.RedisOptions.AddFlags(fss.FlagSet("redis"))
+	o.NotificationOptions.AddFlags(fss.FlagSet("notification"))
 	o.FeatureOptions.AddFlags(fss.FlagSet("features"))
 	o.InsecureServing.AddFlags(fss.FlagSet("insecure serving"))
 	o.SecureServing.AddFlags(fss.FlagSet("secure serving"))
+	o.GRPCOptions.AddFlags(fss.FlagSet("grpc"))
+	o.Authentication.AddFlags(fss.FlagSet("authentication"))
 	o.Log.AddFlags(fss.FlagSet("logs"))
 
 	// Note: the weird ""+ in below lines seems to be the only way to get gofmt to
@@ -60,10 +90,17 @@ func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
 	fs := fss.FlagSet("misc")
 	fs.StringVar(&o.RPCServer, "rpcserver", o.RPCServer, "The address of iam rpc server. "+
 		"The rpc server can provide all the secrets and policies to use.")
+	fs.StringVar(&o.DataDir, "data-dir", o.DataDir, ""+
+		"If set, secrets and policies are read from the YAML/JSON files in the directory instead of "+
+		"the rpc server, and reloaded whenever the files change.")
 	fs.StringVar(&o.ClientCA, "client-ca-file", o.ClientCA, ""+
 		"If set, any request presenting a client certificate signed by one of "+
 		"the authorities in the client-ca-file is authenticated with an identity "+
 		"corresponding to the CommonName of the client certificate.")
+	fs.StringVar(&o.ClientCert, "client-cert-file", o.ClientCert, ""+
+		"Client certificate presented to the rpc server, required when the rpc server requires client certificates.")
+	fs.StringVar(&o.ClientKey, "client-key-file", o.ClientKey, ""+
+		"Private key matching --client-cert-file.")
 
 	return fss
 }
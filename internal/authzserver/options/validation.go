@@ -4,6 +4,8 @@
 
 package options
 
+import "fmt"
+
 // Validate checks Options and return a slice of found errs.
 func (o *Options) Validate() []error {
 	var errs []error
@@ -12,9 +14,19 @@ func (o *Options) Validate() []error {
 	errs = append(errs, o.InsecureServing.Validate()...)
 	errs = append(errs, o.SecureServing.Validate()...)
 	errs = append(errs, o.RedisOptions.Validate()...)
+	errs = append(errs, o.NotificationOptions.Validate()...)
 	errs = append(errs, o.FeatureOptions.Validate()...)
 	errs = append(errs, o.Log.Validate()...)
 	errs = append(errs, o.AnalyticsOptions.Validate()...)
+	errs = append(errs, o.GRPCOptions.Validate()...)
+	errs = append(errs, o.Authentication.Validate()...)
+	errs = append(errs, o.AuthorizationOptions.Validate()...)
+	errs = append(errs, o.CacheOptions.Validate()...)
+	errs = append(errs, o.RateLimitOptions.Validate()...)
+
+	if (o.ClientCert == "") != (o.ClientKey == "") {
+		errs = append(errs, fmt.Errorf("--client-cert-file and --client-key-file must be set together"))
+	}
 
 	return errs
 }
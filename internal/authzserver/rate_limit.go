@@ -0,0 +1,72 @@
+package authzserver
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	"github.com/nico612/iam-demo/internal/pkg/middleware/auth"
+	genericoptions "github.com/nico612/iam-demo/internal/pkg/options"
+	"github.com/nico612/iam-demo/pkg/storage"
+)
+
+// rateLimit limits the requests signed with each secret and the requests of each user, it must be used
+// after the authentication which identifies the caller.
+func rateLimit(opts *genericoptions.RateLimitOptions) gin.HandlerFunc {
+	quotas := newRateLimitQuotas(opts)
+
+	return middleware.RateLimit(&storage.RedisCluster{}, func(c *gin.Context) []middleware.RateLimitQuota {
+		return quotas(c.GetString(middleware.SecretIDKey), c.GetString(middleware.UsernameKey))
+	})
+}
+
+// rateLimitInterceptor applies the same quotas as rateLimit to the grpc calls, it must be chained after
+// the grpc authentication interceptor.
+func rateLimitInterceptor(opts *genericoptions.RateLimitOptions) grpc.UnaryServerInterceptor {
+	quotas := newRateLimitQuotas(opts)
+
+	return middleware.RateLimitUnaryServerInterceptor(
+		&storage.RedisCluster{},
+		func(ctx context.Context) []middleware.RateLimitQuota {
+			return quotas(auth.SecretIDFromContext(ctx), auth.UsernameFromContext(ctx))
+		},
+	)
+}
+
+// newRateLimitQuotas returns the function which builds the quotas of the caller identified by the secret id
+// and the username.
+func newRateLimitQuotas(
+	opts *genericoptions.RateLimitOptions,
+) func(secretID, username string) []middleware.RateLimitQuota {
+	secretLimits, userLimits := opts.SecretLimits(), opts.UserLimits()
+
+	return func(secretID, username string) []middleware.RateLimitQuota {
+		var quotas []middleware.RateLimitQuota
+
+		// 使用 mTLS 认证的请求没有 secret，只按用户限流
+		if secretID != "" {
+			if limit := quotaLimit(secretLimits, secretID, opts.SecretLimit); limit > 0 {
+				quotas = append(quotas, middleware.RateLimitQuota{Key: "secret:" + secretID, Limit: limit, Window: opts.Window})
+			}
+		}
+
+		if username != "" {
+			if limit := quotaLimit(userLimits, username, opts.UserLimit); limit > 0 {
+				quotas = append(quotas, middleware.RateLimitQuota{Key: "user:" + username, Limit: limit, Window: opts.Window})
+			}
+		}
+
+		return quotas
+	}
+}
+
+// quotaLimit returns the specific limit of the key if any, or the default limit.
+func quotaLimit(limits map[string]int, key string, defaultLimit int) int {
+	if limit, ok := limits[key]; ok {
+		return limit
+	}
+
+	return defaultLimit
+}
@@ -0,0 +1,39 @@
+package authzserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/nico612/iam-demo/internal/authzserver/load/cache"
+)
+
+// readyzResponse is the body of the /readyz response.
+type readyzResponse struct {
+	Ready    bool   `json:"ready"`
+	Reason   string `json:"reason,omitempty"`
+	CacheAge string `json:"cacheAge,omitempty"`
+	cache.Status
+}
+
+// readyz reports whether iam-authz-server can serve authorization requests. It returns 503 until the first full
+// load of the secrets and policies succeeds, and when the cache is older than maxStaleness while reloads keep failing.
+func readyz(cacheIns *cache.Cache, maxStaleness time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := cacheIns.Status()
+		ready, reason := status.Ready(maxStaleness)
+
+		httpStatus := http.StatusOK
+		if !ready {
+			httpStatus = http.StatusServiceUnavailable
+		}
+
+		resp := readyzResponse{Ready: ready, Reason: reason, Status: status}
+		if status.Loaded || status.Degraded {
+			resp.CacheAge = status.Age.Truncate(time.Second).String()
+		}
+
+		c.JSON(httpStatus, resp)
+	}
+}
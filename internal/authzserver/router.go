@@ -1,20 +1,30 @@
 package authzserver
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/marmotedu/component-base/pkg/core"
 	"github.com/marmotedu/errors"
 	"github.com/nico612/iam-demo/internal/authzserver/controller/v1/authorize"
 	"github.com/nico612/iam-demo/internal/authzserver/load/cache"
 	"github.com/nico612/iam-demo/internal/pkg/code"
+	genericoptions "github.com/nico612/iam-demo/internal/pkg/options"
 	"github.com/nico612/iam-demo/pkg/log"
 )
 
 func installMiddleware(g *gin.Engine) {
 }
 
-func installController(g *gin.Engine) *gin.Engine {
-	auth := newCacheAuth() // 缓存中间件
+func installController(
+	g *gin.Engine,
+	authentication *genericoptions.AuthenticationOptions,
+	clientCert *genericoptions.ClientCertAuthenticationOptions,
+	rateLimitOptions *genericoptions.RateLimitOptions,
+	maxStaleness time.Duration,
+	maxBatchSize int,
+) *gin.Engine {
+	auth := newAuthChain(authentication.Strategies, clientCert) // 认证链，默认只使用缓存认证
 
 	g.NoRoute(auth.AuthFunc(), func(c *gin.Context) {
 		core.WriteResponse(c, errors.WithCode(code.ErrPageNotFound, "page not found."), nil)
@@ -25,12 +35,21 @@ func installController(g *gin.Engine) *gin.Engine {
 		log.Panicf("get nil cache instance")
 	}
 
-	apiv1 := g.Group("/v1", auth.AuthFunc())
+	// 就绪检查，secrets 和 policies 首次加载完成前返回 503，不需要认证
+	g.GET("/readyz", readyz(cacheIns, maxStaleness))
+
+	// 按 secret 和用户限流，需要在认证之后才能识别调用方
+	apiv1 := g.Group("/v1", auth.AuthFunc(), rateLimit(rateLimitOptions))
 	{
-		authzController := authorize.NewAuthzController(cacheIns)
+		authzController := authorize.NewAuthzController(
+			cacheIns,
+			cacheIns.DecisionCache(),
+			maxBatchSize,
+		)
 
 		// Router for authorization
 		apiv1.POST("/authz", authzController.Authorize)
+		apiv1.POST("/authz/batch", authzController.BatchAuthorize)
 	}
 
 	return g
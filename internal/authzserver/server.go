@@ -6,11 +6,16 @@ import (
 	"github.com/nico612/iam-demo/internal/authzserver/config"
 	"github.com/nico612/iam-demo/internal/authzserver/load"
 	"github.com/nico612/iam-demo/internal/authzserver/load/cache"
+	"github.com/nico612/iam-demo/internal/authzserver/store"
 	"github.com/nico612/iam-demo/internal/authzserver/store/apiserver"
+	"github.com/nico612/iam-demo/internal/authzserver/store/file"
 	"github.com/nico612/iam-demo/pkg/log"
 	"github.com/nico612/iam-demo/pkg/storage"
+	"time"
 
 	"github.com/nico612/iam-demo/internal/authzserver/analytics"
+	"github.com/nico612/iam-demo/internal/authzserver/authorization"
+	"github.com/nico612/iam-demo/internal/pkg/notification"
 	genericoptions "github.com/nico612/iam-demo/internal/pkg/options"
 	genericapiserver "github.com/nico612/iam-demo/internal/pkg/server"
 	"github.com/nico612/iam-demo/pkg/shutdown"
@@ -20,14 +25,29 @@ import (
 // RedisKeyPrefix defines the prefix key in redis for analytics data.
 const RedisKeyPrefix = "analytics-"
 
+// snapshotRecoverInterval is how often a full reload is retried while the cache is served from the snapshot.
+const snapshotRecoverInterval = 10 * time.Second
+
 type authzServer struct {
 	gs               *shutdown.GracefulShutdown         // 优雅关闭
 	rpcServer        string                             // rpc 服务
+	dataDir          string                             // 数据文件目录，设置后不再从 rpc 服务获取数据
 	clientCA         string                             // 客户端 CA
+	clientCert       string                             // 连接 rpc 服务使用的客户端证书
+	clientKey        string                             // 客户端证书私钥
 	redisOptions     *genericoptions.RedisOptions       // redis
 	genericAPIServer *genericapiserver.GenericAPIServer // http/https 服务
+	gRPCAuthzServer  *grpcAuthzServer                   // grpc 授权服务，未启用时为 nil
 	analyticsOptions *analytics.AnalyticsOptions        // 记录分析配置
 	redisCancelFunc  context.CancelFunc
+
+	grpcOptions           *genericoptions.GRPCOptions
+	secureServingOptions  *genericoptions.SecureServingOptions
+	authenticationOptions *genericoptions.AuthenticationOptions
+	authorizationOptions  *authorization.AuthorizationOptions
+	notificationOptions   *genericoptions.NotificationOptions
+	cacheOptions          *cache.CacheOptions
+	rateLimitOptions      *genericoptions.RateLimitOptions
 }
 
 type preparedAuthzServer struct {
@@ -52,10 +72,21 @@ func createAuthzServer(cfg *config.Config) (*authzServer, error) {
 	server := &authzServer{
 		gs:               gs,
 		rpcServer:        cfg.RPCServer,
+		dataDir:          cfg.DataDir,
 		clientCA:         cfg.ClientCA,
+		clientCert:       cfg.ClientCert,
+		clientKey:        cfg.ClientKey,
 		redisOptions:     cfg.RedisOptions,
 		genericAPIServer: genericServer,
 		analyticsOptions: cfg.AnalyticsOptions,
+
+		grpcOptions:           cfg.GRPCOptions,
+		secureServingOptions:  cfg.SecureServing,
+		authenticationOptions: cfg.Authentication,
+		authorizationOptions:  cfg.AuthorizationOptions,
+		notificationOptions:   cfg.NotificationOptions,
+		cacheOptions:          cfg.CacheOptions,
+		rateLimitOptions:      cfg.RateLimitOptions,
 	}
 
 	return server, nil
@@ -63,9 +94,34 @@ func createAuthzServer(cfg *config.Config) (*authzServer, error) {
 
 // PrepareRun 初始化相关服务 和 注册路由
 func (s *authzServer) PrepareRun() preparedAuthzServer {
-	_ = s.initialize()
+	if err := s.initialize(); err != nil {
+		log.Fatalf("initialize authz server failed: %s", err.Error())
+	}
+
+	installController(
+		s.genericAPIServer.Engine,
+		s.authenticationOptions,
+		&s.secureServingOptions.ClientCert,
+		s.rateLimitOptions,
+		s.cacheOptions.MaxStaleness,
+		s.authorizationOptions.MaxBatchSize,
+	)
 
-	installController(s.genericAPIServer.Engine)
+	// bind-port 为 0 时不启用 grpc 授权服务
+	if s.grpcOptions.BindPort != 0 {
+		grpcServer, err := newGRPCAuthzServer(
+			s.grpcOptions,
+			s.secureServingOptions,
+			s.authenticationOptions,
+			s.rateLimitOptions,
+			s.authorizationOptions.MaxBatchSize,
+		)
+		if err != nil {
+			log.Fatalf("create grpc server failed: %s", err.Error())
+		}
+
+		s.gRPCAuthzServer = grpcServer
+	}
 
 	return preparedAuthzServer{s}
 }
@@ -76,6 +132,9 @@ func (s preparedAuthzServer) Run() error {
 	// please ensure the following graceful shutdown sequence
 	s.gs.AddShutdownCallback(shutdown.ShutdownFunc(func(string) error {
 		s.genericAPIServer.Close()
+		if s.gRPCAuthzServer != nil {
+			s.gRPCAuthzServer.Close()
+		}
 		if s.analyticsOptions.Enable {
 			analytics.GetAnalytics().Stop()
 		}
@@ -89,6 +148,10 @@ func (s preparedAuthzServer) Run() error {
 		log.Fatalf("start shutdown manager failed: %s", err.Error())
 	}
 
+	if s.gRPCAuthzServer != nil {
+		s.gRPCAuthzServer.Run()
+	}
+
 	return s.genericAPIServer.Run()
 }
 
@@ -139,15 +202,62 @@ func (s *authzServer) initialize() error {
 	// keep redis connected
 	go storage.ConnectToRedis(ctx, s.buildStorageConfig())
 
-	// cron to reload all secrets and policies from iam-apiserver
+	// cron to reload all secrets and policies from iam-apiserver or the data files
 	// 缓存实例
-	cacheIns, err := cache.GetCacheInsOr(apiserver.GetAPIServerFactoryOrDie(s.rpcServer, s.clientCA))
+	storeIns, err := s.newStore()
+	if err != nil {
+		return err
+	}
+
+	cacheIns, err := cache.GetCacheInsOr(storeIns)
 	if err != nil {
 		return errors.Wrap(err, "get cache instance failed")
 	}
 
+	// 授权结果缓存，缓存重新加载时失效
+	if s.authorizationOptions.DecisionCacheSize > 0 {
+		decisions, err := cache.NewDecisionCache(
+			s.authorizationOptions.DecisionCacheSize,
+			s.authorizationOptions.DecisionCacheTTL,
+		)
+		if err != nil {
+			return errors.Wrap(err, "create decision cache failed")
+		}
+
+		cacheIns.SetDecisionCache(decisions)
+	}
+
+	// 先加载本地快照，数据源不可用时以降级模式提供服务
+	if s.cacheOptions.SnapshotPath != "" {
+		snapshotter, err := cache.NewSnapshotter(s.cacheOptions.SnapshotPath, s.cacheOptions.SnapshotKey)
+		if err != nil {
+			return errors.Wrap(err, "create cache snapshotter failed")
+		}
+
+		cacheIns.SetSnapshotter(snapshotter)
+
+		if err := cacheIns.LoadSnapshot(); err != nil {
+			log.Warnf("failed to load cache snapshot: %s", err.Error())
+		}
+	}
+
 	// 初始化 load 并开启 订阅 redis 服务, 当有缓存需要更新时执行更新本地缓存
-	load.NewLoader(ctx, cacheIns).Start()
+	verifier := notification.NewVerifier(s.notificationOptions.Keys, s.notificationOptions.ReplayWindow)
+	loader := load.NewLoader(ctx, cacheIns, verifier)
+	loader.Start()
+
+	// 定期全量同步，避免丢失通知后缓存一直不更新
+	loader.StartResync(s.cacheOptions.ResyncPeriod)
+
+	// 降级模式下持续重试全量加载，直到数据源恢复
+	go recoverFromSnapshot(ctx, loader, cacheIns)
+
+	// 数据文件变化时重新加载全部 secrets 和 policies
+	if s.dataDir != "" {
+		if err := file.Watch(ctx, s.dataDir, loader.QueueReload); err != nil {
+			return errors.Wrap(err, "watch data directory failed")
+		}
+	}
 
 	// 日志上报功能， start analytics service, 并将分析日志写入redis中
 	if s.analyticsOptions.Enable {
@@ -160,3 +270,34 @@ func (s *authzServer) initialize() error {
 
 	return nil
 }
+
+// recoverFromSnapshot reloads all the secrets and policies periodically while the cache is served from the snapshot.
+func recoverFromSnapshot(ctx context.Context, loader *load.Load, cacheIns *cache.Cache) {
+	ticker := time.NewTicker(snapshotRecoverInterval)
+	defer ticker.Stop()
+
+	for cacheIns.Status().Degraded {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			loader.DoReload()
+		}
+	}
+}
+
+// newStore returns the store to read secrets and policies from, the data files take precedence over iam-apiserver.
+func (s *authzServer) newStore() (store.Factory, error) {
+	if s.dataDir == "" {
+		return apiserver.GetAPIServerFactoryOrDie(s.rpcServer, s.clientCA, s.clientCert, s.clientKey), nil
+	}
+
+	storeIns, err := file.NewFileFactory(s.dataDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "create file store failed")
+	}
+
+	log.Infof("read secrets and policies from data directory %s", s.dataDir)
+
+	return storeIns, nil
+}
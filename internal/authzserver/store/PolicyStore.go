@@ -4,4 +4,5 @@ import "github.com/ory/ladon"
 
 type PolicyStore interface {
 	List() (map[string][]*ladon.DefaultPolicy, error)
+	ListByUser(username string) ([]*ladon.DefaultPolicy, error)
 }
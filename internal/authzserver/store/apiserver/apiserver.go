@@ -1,16 +1,24 @@
 package apiserver
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/avast/retry-go"
 	pb "github.com/marmotedu/api/proto/apiserver/v1"
+	"github.com/marmotedu/errors"
+	deltapb "github.com/nico612/iam-demo/api/proto/apiserver/v1"
 	"github.com/nico612/iam-demo/internal/authzserver/store"
 	"github.com/nico612/iam-demo/pkg/log"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"os"
 	"sync"
 )
 
 type datastore struct {
-	cli pb.CacheClient
+	cli   pb.CacheClient
+	delta deltapb.CacheDeltaClient
 }
 
 func (ds *datastore) Secrets() store.SecretStore {
@@ -21,32 +29,58 @@ func (ds *datastore) Policies() store.PolicyStore {
 	return newPolicies(ds)
 }
 
+func (ds *datastore) Users() store.UserStore {
+	return newUsers(ds)
+}
+
+// Revision returns the revision computed by iam-apiserver.
+func (ds *datastore) Revision() (string, error) {
+	var resp *deltapb.GetRevisionResponse
+
+	err := retry.Do(func() error {
+		var getErr error
+
+		resp, getErr = ds.delta.GetRevision(context.Background(), &deltapb.GetRevisionRequest{})
+
+		return getErr
+	}, retry.Attempts(3))
+	if err != nil {
+		return "", errors.Wrap(err, "get revision failed")
+	}
+
+	return resp.Revision, nil
+}
+
 var (
 	apiServerFactory store.Factory
 	once             sync.Once
 )
 
-// GetAPIServerFactoryOrDie return cache instance and panics on any error.
-func GetAPIServerFactoryOrDie(address string, clientCA string) store.Factory {
+// GetAPIServerFactoryOrDie return cache instance and panics on any error. The rpc server is verified with
+// clientCA, the client certificate is presented when certFile and keyFile are set.
+func GetAPIServerFactoryOrDie(address, clientCA, certFile, keyFile string) store.Factory {
 	once.Do(func() {
 		var (
-			err   error
-			conn  *grpc.ClientConn
-			creds credentials.TransportCredentials
+			err       error
+			conn      *grpc.ClientConn
+			tlsConfig *tls.Config
 		)
 
-		creds, err = credentials.NewClientTLSFromFile(clientCA, "")
+		tlsConfig, err = newClientTLSConfig(clientCA, certFile, keyFile)
 		if err != nil {
-			log.Panicf("credentials.NewClientTLSFromFile err: %v", err)
+			log.Panicf("create client tls config failed: %v", err)
 		}
 
-		conn, err = grpc.Dial(address, grpc.WithBlock(), grpc.WithTransportCredentials(creds))
+		creds := credentials.NewTLS(tlsConfig)
+
+		// 不阻塞等待连接建立，iam-apiserver 不可用时 iam-authz-server 仍然可以启动，并使用本地快照提供服务
+		conn, err = grpc.Dial(address, grpc.WithTransportCredentials(creds))
 		if err != nil {
 			log.Panicf("Connect to grpc server failed, error: %s", err.Error())
 		}
 
-		apiServerFactory = &datastore{pb.NewCacheClient(conn)}
-		log.Infof("Connected to grpc server, address: %s", address)
+		apiServerFactory = &datastore{cli: pb.NewCacheClient(conn), delta: deltapb.NewCacheDeltaClient(conn)}
+		log.Infof("Created grpc client, address: %s", address)
 	})
 
 	if apiServerFactory == nil {
@@ -55,3 +89,29 @@ func GetAPIServerFactoryOrDie(address string, clientCA string) store.Factory {
 
 	return apiServerFactory
 }
+
+// newClientTLSConfig creates the tls config of the rpc client, the certificate is presented to the rpc servers
+// which require client certificates.
+func newClientTLSConfig(clientCA, certFile, keyFile string) (*tls.Config, error) {
+	caCert, err := os.ReadFile(clientCA)
+	if err != nil {
+		return nil, errors.Wrap(err, "read client ca failed")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, errors.Errorf("no certificate found in %s", clientCA)
+	}
+
+	tlsConfig := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "load client certificate failed")
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
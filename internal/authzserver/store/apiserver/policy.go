@@ -7,16 +7,18 @@ import (
 	"github.com/avast/retry-go"
 	pb "github.com/marmotedu/api/proto/apiserver/v1"
 	"github.com/marmotedu/errors"
+	deltapb "github.com/nico612/iam-demo/api/proto/apiserver/v1"
 	"github.com/nico612/iam-demo/pkg/log"
 	"github.com/ory/ladon"
 )
 
 type policies struct {
-	cli pb.CacheClient
+	cli   pb.CacheClient
+	delta deltapb.CacheDeltaClient
 }
 
 func newPolicies(ds *datastore) *policies {
-	return &policies{cli: ds.cli}
+	return &policies{cli: ds.cli, delta: ds.delta}
 }
 
 func (p *policies) List() (map[string][]*ladon.DefaultPolicy, error) {
@@ -64,3 +66,34 @@ func (p *policies) List() (map[string][]*ladon.DefaultPolicy, error) {
 
 	return pols, nil
 }
+
+// ListByUser returns all the policies of the given user.
+func (p *policies) ListByUser(username string) ([]*ladon.DefaultPolicy, error) {
+	var resp *deltapb.ListUserPoliciesResponse
+
+	err := retry.Do(func() error {
+		var listErr error
+
+		resp, listErr = p.delta.ListUserPolicies(context.Background(), &deltapb.ListUserPoliciesRequest{Username: username})
+
+		return listErr
+	}, retry.Attempts(3))
+	if err != nil {
+		return nil, errors.Wrapf(err, "list policies of user %s failed", username)
+	}
+
+	pols := make([]*ladon.DefaultPolicy, 0, len(resp.Items))
+	for _, v := range resp.Items {
+		var policy ladon.DefaultPolicy
+
+		if err := json.Unmarshal([]byte(v.PolicyShadow), &policy); err != nil {
+			log.Warnf("failed to load policy for %s, error: %s", v.Name, err.Error())
+
+			continue
+		}
+
+		pols = append(pols, &policy)
+	}
+
+	return pols, nil
+}
@@ -6,16 +6,22 @@ import (
 	"github.com/avast/retry-go"
 	pb "github.com/marmotedu/api/proto/apiserver/v1"
 	"github.com/marmotedu/errors"
+	deltapb "github.com/nico612/iam-demo/api/proto/apiserver/v1"
+	"github.com/nico612/iam-demo/internal/authzserver/store"
 	"github.com/nico612/iam-demo/pkg/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type secrets struct {
-	cli pb.CacheClient
+	cli   pb.CacheClient
+	delta deltapb.CacheDeltaClient
 }
 
 func newSecrets(ds *datastore) *secrets {
 	return &secrets{
-		cli: ds.cli,
+		cli:   ds.cli,
+		delta: ds.delta,
 	}
 }
 
@@ -54,3 +60,39 @@ func (s *secrets) List() (map[string]*pb.SecretInfo, error) {
 
 	return secretInfos, nil
 }
+
+// Get returns the secret of the user with the given secret id, store.ErrSecretNotFound if it does not exist.
+func (s *secrets) Get(username, secretID string) (*pb.SecretInfo, error) {
+	var resp *deltapb.GetSecretResponse
+
+	req := &deltapb.GetSecretRequest{Username: username, SecretId: secretID}
+	err := retry.Do(func() error {
+		var getErr error
+
+		resp, getErr = s.delta.GetSecret(context.Background(), req)
+
+		return getErr
+	}, retry.Attempts(3), retry.RetryIf(func(err error) bool {
+		return status.Code(err) != codes.NotFound
+	}))
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, store.ErrSecretNotFound
+		}
+
+		return nil, errors.Wrapf(err, "get secret %s failed", secretID)
+	}
+
+	secret := resp.Secret
+
+	return &pb.SecretInfo{
+		Name:        secret.Name,
+		SecretId:    secret.SecretId,
+		Username:    secret.Username,
+		SecretKey:   secret.SecretKey,
+		Expires:     secret.Expires,
+		Description: secret.Description,
+		CreatedAt:   secret.CreatedAt,
+		UpdatedAt:   secret.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package apiserver
+
+import (
+	"context"
+
+	"github.com/avast/retry-go"
+	"github.com/marmotedu/errors"
+
+	deltapb "github.com/nico612/iam-demo/api/proto/apiserver/v1"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+type users struct {
+	delta deltapb.CacheDeltaClient
+}
+
+func newUsers(ds *datastore) *users {
+	return &users{delta: ds.delta}
+}
+
+// List returns the attributes of all the users, keyed by username.
+func (u *users) List() (map[string]map[string]interface{}, error) {
+	log.Info("Loading user attributes")
+
+	return u.list("")
+}
+
+// Get returns the attributes of the given user, nil if the user has no attributes.
+func (u *users) Get(username string) (map[string]interface{}, error) {
+	attributes, err := u.list(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return attributes[username], nil
+}
+
+func (u *users) list(username string) (map[string]map[string]interface{}, error) {
+	var resp *deltapb.ListUserAttributesResponse
+
+	err := retry.Do(func() error {
+		var listErr error
+
+		resp, listErr = u.delta.ListUserAttributes(
+			context.Background(),
+			&deltapb.ListUserAttributesRequest{Username: username},
+		)
+
+		return listErr
+	}, retry.Attempts(3))
+	if err != nil {
+		return nil, errors.Wrap(err, "list user attributes failed")
+	}
+
+	attributes := make(map[string]map[string]interface{}, len(resp.Attributes))
+	for name, value := range resp.Attributes {
+		attributes[name] = value.AsMap()
+	}
+
+	return attributes, nil
+}
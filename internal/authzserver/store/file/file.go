@@ -0,0 +1,232 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+	"gopkg.in/yaml.v3"
+
+	"github.com/nico612/iam-demo/internal/authzserver/store"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// file 存储：从目录下的 YAML/JSON 文件中读取用户属性、secrets 和 policies，不依赖 iam-apiserver，
+// 适用于边缘站点独立部署和集成测试。每次读取都会重新解析目录下的全部文件.
+
+// dataFile is the content of a data file, one directory can hold any number of data files.
+//
+//	secrets:
+//	  - username: colin
+//	    secretID: Jv4oPYCFMthT0lbJp6vULZBhJoKAazFJmMxt
+//	    secretKey: BQUHUYy2qOSy8oBKDXLQF6WWRaR0O1gI
+//	users:
+//	  - username: colin
+//	    extend:
+//	      department: engineering
+//	policies:
+//	  - username: colin
+//	    name: policy1
+//	    policy:
+//	      subjects: ["users:<.*>"]
+//	      resources: ["resources:articles:<.*>"]
+//	      actions: ["delete", "<create|update>"]
+//	      effect: allow
+type dataFile struct {
+	Users    []*user   `json:"users"`
+	Secrets  []*secret `json:"secrets"`
+	Policies []*policy `json:"policies"`
+}
+
+// user carries the attributes of a user, like the `Extend` data of the users of iam-apiserver.
+type user struct {
+	Username string                 `json:"username"`
+	Extend   map[string]interface{} `json:"extend"`
+}
+
+type secret struct {
+	Name        string `json:"name"`
+	Username    string `json:"username"`
+	SecretID    string `json:"secretID"`
+	SecretKey   string `json:"secretKey"`
+	Expires     int64  `json:"expires"`
+	Description string `json:"description"`
+}
+
+type policy struct {
+	Name     string              `json:"name"`
+	Username string              `json:"username"`
+	Policy   ladon.DefaultPolicy `json:"policy"`
+}
+
+type datastore struct {
+	dir string
+}
+
+func (ds *datastore) Secrets() store.SecretStore {
+	return newSecrets(ds)
+}
+
+func (ds *datastore) Policies() store.PolicyStore {
+	return newPolicies(ds)
+}
+
+func (ds *datastore) Users() store.UserStore {
+	return newUsers(ds)
+}
+
+// Revision returns a checksum of the names and contents of all the data files in the directory.
+func (ds *datastore) Revision() (string, error) {
+	entries, err := os.ReadDir(ds.dir)
+	if err != nil {
+		return "", errors.Wrap(err, "read data directory failed")
+	}
+
+	h := sha256.New()
+	for _, entry := range entries {
+		if entry.IsDir() || !isDataFile(entry.Name()) {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(ds.dir, entry.Name()))
+		if err != nil {
+			return "", errors.Wrapf(err, "read data file %s failed", entry.Name())
+		}
+
+		h.Write([]byte(entry.Name()))
+		h.Write([]byte{0})
+		h.Write(content)
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+var _ store.Factory = (*datastore)(nil)
+
+// NewFileFactory creates a store factory which reads secrets and policies from the data files in dir.
+func NewFileFactory(dir string) (store.Factory, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "open data directory failed")
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	return &datastore{dir: dir}, nil
+}
+
+// Watch calls onChange whenever a data file in dir is created, changed or removed, until ctx is done.
+func Watch(ctx context.Context, dir string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "create file watcher failed")
+	}
+
+	if err := watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+
+		return errors.Wrapf(err, "watch data directory %s failed", dir)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if !isDataFile(event.Name) || event.Op == fsnotify.Chmod {
+					continue
+				}
+
+				log.Infof("data file %s changed: %s", event.Name, event.Op.String())
+				onChange()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				log.Errorf("watch data directory failed: %s", err.Error())
+			}
+		}
+	}()
+
+	return nil
+}
+
+// load reads and merges all the data files in the directory.
+func (ds *datastore) load() (*dataFile, error) {
+	entries, err := os.ReadDir(ds.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "read data directory failed")
+	}
+
+	data := &dataFile{}
+	for _, entry := range entries {
+		if entry.IsDir() || !isDataFile(entry.Name()) {
+			continue
+		}
+
+		file, err := readDataFile(filepath.Join(ds.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		data.Users = append(data.Users, file.Users...)
+		data.Secrets = append(data.Secrets, file.Secrets...)
+		data.Policies = append(data.Policies, file.Policies...)
+	}
+
+	return data, nil
+}
+
+func readDataFile(path string) (*dataFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read data file %s failed", path)
+	}
+
+	// YAML 先转换为 JSON，ladon 策略的 conditions 只支持 JSON 解码
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		var value interface{}
+		if err := yaml.Unmarshal(content, &value); err != nil {
+			return nil, errors.Wrapf(err, "decode data file %s failed", path)
+		}
+
+		if content, err = json.Marshal(value); err != nil {
+			return nil, errors.Wrapf(err, "decode data file %s failed", path)
+		}
+	}
+
+	file := &dataFile{}
+	if err := json.Unmarshal(content, file); err != nil {
+		return nil, errors.Wrapf(err, "decode data file %s failed", path)
+	}
+
+	return file, nil
+}
+
+func isDataFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
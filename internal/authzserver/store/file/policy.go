@@ -0,0 +1,55 @@
+package file
+
+import (
+	"github.com/ory/ladon"
+)
+
+type policies struct {
+	ds *datastore
+}
+
+func newPolicies(ds *datastore) *policies {
+	return &policies{ds: ds}
+}
+
+// List returns the policies of all the users, keyed by username.
+func (p *policies) List() (map[string][]*ladon.DefaultPolicy, error) {
+	data, err := p.ds.load()
+	if err != nil {
+		return nil, err
+	}
+
+	pols := make(map[string][]*ladon.DefaultPolicy)
+	for _, v := range data.Policies {
+		pols[v.Username] = append(pols[v.Username], toLadonPolicy(v))
+	}
+
+	return pols, nil
+}
+
+// ListByUser returns all the policies of the given user.
+func (p *policies) ListByUser(username string) ([]*ladon.DefaultPolicy, error) {
+	data, err := p.ds.load()
+	if err != nil {
+		return nil, err
+	}
+
+	pols := make([]*ladon.DefaultPolicy, 0)
+	for _, v := range data.Policies {
+		if v.Username == username {
+			pols = append(pols, toLadonPolicy(v))
+		}
+	}
+
+	return pols, nil
+}
+
+// toLadonPolicy returns the ladon policy, its id is the policy name like the policies of iam-apiserver.
+func toLadonPolicy(p *policy) *ladon.DefaultPolicy {
+	policy := p.Policy
+	if p.Name != "" {
+		policy.ID = p.Name
+	}
+
+	return &policy
+}
@@ -0,0 +1,57 @@
+package file
+
+import (
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+
+	"github.com/nico612/iam-demo/internal/authzserver/store"
+)
+
+type secrets struct {
+	ds *datastore
+}
+
+func newSecrets(ds *datastore) *secrets {
+	return &secrets{ds: ds}
+}
+
+// List returns all the authorization secrets.
+func (s *secrets) List() (map[string]*pb.SecretInfo, error) {
+	data, err := s.ds.load()
+	if err != nil {
+		return nil, err
+	}
+
+	secretInfos := make(map[string]*pb.SecretInfo, len(data.Secrets))
+	for _, v := range data.Secrets {
+		secretInfos[v.SecretID] = toSecretInfo(v)
+	}
+
+	return secretInfos, nil
+}
+
+// Get returns the secret of the user with the given secret id, store.ErrSecretNotFound if it does not exist.
+func (s *secrets) Get(username, secretID string) (*pb.SecretInfo, error) {
+	data, err := s.ds.load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range data.Secrets {
+		if v.Username == username && v.SecretID == secretID {
+			return toSecretInfo(v), nil
+		}
+	}
+
+	return nil, store.ErrSecretNotFound
+}
+
+func toSecretInfo(s *secret) *pb.SecretInfo {
+	return &pb.SecretInfo{
+		Name:        s.Name,
+		SecretId:    s.SecretID,
+		Username:    s.Username,
+		SecretKey:   s.SecretKey,
+		Expires:     s.Expires,
+		Description: s.Description,
+	}
+}
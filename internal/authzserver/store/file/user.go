@@ -0,0 +1,36 @@
+package file
+
+type users struct {
+	ds *datastore
+}
+
+func newUsers(ds *datastore) *users {
+	return &users{ds: ds}
+}
+
+// List returns the attributes of all the users, keyed by username.
+func (u *users) List() (map[string]map[string]interface{}, error) {
+	data, err := u.ds.load()
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make(map[string]map[string]interface{}, len(data.Users))
+	for _, v := range data.Users {
+		if len(v.Extend) > 0 {
+			attributes[v.Username] = v.Extend
+		}
+	}
+
+	return attributes, nil
+}
+
+// Get returns the attributes of the given user, nil if the user has no attributes.
+func (u *users) Get(username string) (map[string]interface{}, error) {
+	attributes, err := u.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return attributes[username], nil
+}
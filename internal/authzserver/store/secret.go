@@ -1,7 +1,15 @@
 package store
 
-import pb "github.com/marmotedu/api/proto/apiserver/v1"
+import (
+	"errors"
+
+	pb "github.com/marmotedu/api/proto/apiserver/v1"
+)
+
+// ErrSecretNotFound is returned by SecretStore.Get when the secret does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
 
 type SecretStore interface {
 	List() (map[string]*pb.SecretInfo, error)
+	Get(username, secretID string) (*pb.SecretInfo, error)
 }
@@ -3,6 +3,10 @@ package store
 type Factory interface {
 	Secrets() SecretStore
 	Policies() PolicyStore
+	Users() UserStore
+	// Revision returns a checksum of all the secrets, policies and user attributes, it changes
+	// whenever any of them changes.
+	Revision() (string, error)
 }
 
 var client Factory
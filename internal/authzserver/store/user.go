@@ -0,0 +1,7 @@
+package store
+
+// UserStore provides the attributes of the users, which are the `Extend` data of the users.
+type UserStore interface {
+	List() (map[string]map[string]interface{}, error)
+	Get(username string) (map[string]interface{}, error)
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 Lingfei Kong <colin404@foxmail.com>. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package authorization
+
+import (
+	authzv1 "github.com/marmotedu/api/authz/v1"
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/pkg/log"
+	"github.com/ory/ladon"
+)
+
+// Authorizer implement the authorizer interface that use local repository to
+// authorizer the subject access review. The policies are looked up with a manager scoped to the
+// user of each request.
+type Authorizer struct {
+	client      AuthorizationInterface
+	auditLogger *AuditLogger
+	decisions   DecisionCache
+}
+
+// NewAuthorizer creates a local repository authorizer and returns it.
+func NewAuthorizer(authorizationClient AuthorizationInterface) *Authorizer {
+	return NewCachedAuthorizer(authorizationClient, nil)
+}
+
+// NewCachedAuthorizer creates a local repository authorizer which serves repeated requests from
+// the decision cache. The decision cache is not used when decisions is nil.
+// The authorizer is not safe for concurrent use.
+func NewCachedAuthorizer(authorizationClient AuthorizationInterface, decisions DecisionCache) *Authorizer {
+	return &Authorizer{
+		client:      authorizationClient,
+		auditLogger: NewAuditLogger(authorizationClient),
+		decisions:   decisions,
+	}
+}
+
+// manager returns the policy manager of the user of the request.
+func (a *Authorizer) manager(request *ladon.Request) ladon.Manager {
+	return NewPolicyManager(a.client, RequestUsername(request))
+}
+
+// Authorize to determine the subject access.
+func (a *Authorizer) Authorize(request *ladon.Request) *Response {
+	log.Debug("authorizer request", log.Any("request", request))
+
+	if a.decisions == nil {
+		return a.authorize(request)
+	}
+
+	key, ok := DecisionKey(request)
+	if !ok {
+		return a.authorize(request)
+	}
+
+	generation := a.decisions.Generation()
+	if decision, ok := a.decisions.GetDecision(generation, key); ok {
+		// 命中缓存时同样记录审计日志
+		if decision.Response.Allowed {
+			a.client.LogGrantedAccessRequest(request, decision.Policies, decision.Deciders)
+		} else {
+			a.client.LogRejectedAccessRequest(request, decision.Policies, decision.Deciders)
+		}
+
+		rsp := *decision.Response
+
+		return &rsp
+	}
+
+	rsp := a.authorize(request)
+
+	// 只缓存 ladon 完成了策略评估的结果，获取策略失败等错误不缓存；
+	// 候选策略包含时间条件时，结果会随时间变化，同样不缓存
+	if a.auditLogger.logged && !hasTimeConditions(a.auditLogger.policies) {
+		cached := *rsp
+		a.decisions.SetDecision(generation, key, &Decision{
+			Response: &cached,
+			Policies: a.auditLogger.policies,
+			Deciders: a.auditLogger.deciders,
+		})
+	}
+
+	return rsp
+}
+
+func (a *Authorizer) authorize(request *ladon.Request) *Response {
+	a.auditLogger.reset()
+
+	warden := &ladon.Ladon{
+		Manager:     a.manager(request),
+		AuditLogger: a.auditLogger,
+	}
+
+	err := warden.IsAllowed(request)
+	if err == nil {
+		return &Response{Response: authzv1.Response{Allowed: true}}
+	}
+
+	rsp := &Response{
+		Response: authzv1.Response{
+			Denied: true,
+			Reason: err.Error(),
+		},
+		ReasonCode: code.ErrAuthorizationFailed,
+	}
+
+	// ladon 完成了策略评估才会记录审计日志，否则是获取或者匹配策略失败
+	if a.auditLogger.logged {
+		rsp.ReasonCode, rsp.Policy = DenialReason(request, a.auditLogger.policies, a.auditLogger.deciders)
+	}
+
+	return rsp
+}
+
+// Explain determines the subject access like Authorize, and explains how the candidate policies lead to the decision.
+func (a *Authorizer) Explain(request *ladon.Request) *ExplainResponse {
+	rsp := &ExplainResponse{Response: a.Authorize(request)}
+
+	policies, err := a.manager(request).FindRequestCandidates(request)
+	if err != nil {
+		rsp.Explanation = &Explanation{Error: err.Error()}
+
+		return rsp
+	}
+
+	rsp.Explanation = explain(request, policies)
+
+	return rsp
+}
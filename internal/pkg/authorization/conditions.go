@@ -0,0 +1,370 @@
+package authorization
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+)
+
+// 自定义 ladon 条件，iam-apiserver 创建策略时校验，iam-authz-server 授权时计算。
+// 时间相关的条件使用服务器当前时间，不信任请求中携带的时间。
+
+// ContextKeyUserAttributes is the request context key of the attributes (the `Extend` data) of the
+// authenticated user. It is set by iam-authz-server, the value given by the caller is overwritten.
+const ContextKeyUserAttributes = "userAttributes"
+
+// Names of the IAM-specific conditions.
+const (
+	TimeWindowConditionName    = "TimeWindowCondition"
+	DateRangeConditionName     = "DateRangeCondition"
+	ResourceOwnerConditionName = "ResourceOwnerCondition"
+	UserAttributeConditionName = "UserAttributeCondition"
+)
+
+// now returns the current time, conditions never use the time from the request.
+var now = time.Now
+
+// nolint: gochecknoinits
+func init() {
+	ladon.ConditionFactories[TimeWindowConditionName] = func() ladon.Condition {
+		return new(TimeWindowCondition)
+	}
+	ladon.ConditionFactories[DateRangeConditionName] = func() ladon.Condition {
+		return new(DateRangeCondition)
+	}
+	ladon.ConditionFactories[ResourceOwnerConditionName] = func() ladon.Condition {
+		return new(ResourceOwnerCondition)
+	}
+	ladon.ConditionFactories[UserAttributeConditionName] = func() ladon.Condition {
+		return new(UserAttributeCondition)
+	}
+}
+
+// conditionValidator is implemented by the conditions whose options can be invalid.
+type conditionValidator interface {
+	Validate() error
+}
+
+// ValidateConditions checks the options of the IAM-specific conditions of a policy.
+func ValidateConditions(conditions ladon.Conditions) error {
+	for key, condition := range conditions {
+		v, ok := condition.(conditionValidator)
+		if !ok {
+			continue
+		}
+
+		if err := v.Validate(); err != nil {
+			return errors.Errorf("invalid condition `%s`: %s", key, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// hasTimeConditions reports whether any of the policies has a condition depending on the current time,
+// the decisions made with these policies may change without any policy change.
+func hasTimeConditions(policies ladon.Policies) bool {
+	for _, policy := range policies {
+		for _, condition := range policy.GetConditions() {
+			switch condition.GetName() {
+			case TimeWindowConditionName, DateRangeConditionName:
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// TimeWindowCondition is fulfilled when the current time is within the daily window on one of the weekdays.
+// Start and end are formatted as `15:04`, a window whose end is before its start spans midnight.
+// An empty window matches the whole day, and empty weekdays match every day.
+type TimeWindowCondition struct {
+	Start    string   `json:"start,omitempty"`
+	End      string   `json:"end,omitempty"`
+	Weekdays []string `json:"weekdays,omitempty"`
+	Timezone string   `json:"timezone,omitempty"`
+}
+
+// GetName returns the condition's name.
+func (c *TimeWindowCondition) GetName() string {
+	return TimeWindowConditionName
+}
+
+// Fulfills returns true if the current time is within the window, the value is ignored.
+func (c *TimeWindowCondition) Fulfills(_ interface{}, _ *ladon.Request) bool {
+	loc, start, end, err := c.parse()
+	if err != nil {
+		return false
+	}
+
+	t := now().In(loc)
+	if len(c.Weekdays) > 0 && !c.matchWeekday(t.Weekday()) {
+		return false
+	}
+
+	if c.Start == "" && c.End == "" {
+		return true
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+
+	return minute >= start || minute < end
+}
+
+// Validate checks the window, weekdays and timezone.
+func (c *TimeWindowCondition) Validate() error {
+	if _, _, _, err := c.parse(); err != nil {
+		return err
+	}
+
+	for _, day := range c.Weekdays {
+		if _, err := parseWeekday(day); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *TimeWindowCondition) parse() (loc *time.Location, start, end int, err error) {
+	if loc, err = time.LoadLocation(c.Timezone); err != nil {
+		return nil, 0, 0, fmt.Errorf("unknown timezone `%s`", c.Timezone)
+	}
+
+	if (c.Start == "") != (c.End == "") {
+		return nil, 0, 0, fmt.Errorf("start and end must be set together")
+	}
+
+	if c.Start == "" {
+		return loc, 0, 0, nil
+	}
+
+	if start, err = parseClock(c.Start); err != nil {
+		return nil, 0, 0, err
+	}
+
+	if end, err = parseClock(c.End); err != nil {
+		return nil, 0, 0, err
+	}
+
+	return loc, start, end, nil
+}
+
+func (c *TimeWindowCondition) matchWeekday(weekday time.Weekday) bool {
+	for _, day := range c.Weekdays {
+		if d, err := parseWeekday(day); err == nil && d == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseClock returns the minute of the day of a `15:04` formatted time.
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time `%s`, expect format 15:04", clock)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseWeekday accepts both the full and the three-letter english names of a weekday, case insensitively.
+func parseWeekday(day string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := d.String()
+		if strings.EqualFold(day, name) || strings.EqualFold(day, name[:3]) {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid weekday `%s`", day)
+}
+
+// DateRangeCondition is fulfilled when the current time is within the validity range. The bounds are
+// formatted as RFC3339 or `2006-01-02`, a date bound includes the whole day in the timezone.
+// An empty bound is unlimited.
+type DateRangeCondition struct {
+	NotBefore string `json:"notBefore,omitempty"`
+	NotAfter  string `json:"notAfter,omitempty"`
+	Timezone  string `json:"timezone,omitempty"`
+}
+
+// GetName returns the condition's name.
+func (c *DateRangeCondition) GetName() string {
+	return DateRangeConditionName
+}
+
+// Fulfills returns true if the current time is within the range, the value is ignored.
+func (c *DateRangeCondition) Fulfills(_ interface{}, _ *ladon.Request) bool {
+	notBefore, notAfter, err := c.parse()
+	if err != nil {
+		return false
+	}
+
+	t := now()
+	if !notBefore.IsZero() && t.Before(notBefore) {
+		return false
+	}
+
+	return notAfter.IsZero() || t.Before(notAfter)
+}
+
+// Validate checks the range and timezone.
+func (c *DateRangeCondition) Validate() error {
+	notBefore, notAfter, err := c.parse()
+	if err != nil {
+		return err
+	}
+
+	if !notBefore.IsZero() && !notAfter.IsZero() && !notBefore.Before(notAfter) {
+		return fmt.Errorf("notBefore must be earlier than notAfter")
+	}
+
+	return nil
+}
+
+// parse returns the inclusive start and the exclusive end of the range.
+func (c *DateRangeCondition) parse() (notBefore, notAfter time.Time, err error) {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown timezone `%s`", c.Timezone)
+	}
+
+	if c.NotBefore != "" {
+		if notBefore, err = parseDate(c.NotBefore, loc, false); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	if c.NotAfter != "" {
+		if notAfter, err = parseDate(c.NotAfter, loc, true); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	return notBefore, notAfter, nil
+}
+
+// parseDate parses a RFC3339 time or a date, the end of the date is returned for an inclusive upper bound.
+func parseDate(value string, loc *time.Location, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		if end {
+			t = t.Add(time.Nanosecond)
+		}
+
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date `%s`, expect RFC3339 or format 2006-01-02", value)
+	}
+
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t, nil
+}
+
+// ResourceOwnerCondition is fulfilled when the value, the owner attribute of the resource given in the
+// request context, is the authenticated user.
+type ResourceOwnerCondition struct{}
+
+// GetName returns the condition's name.
+func (c *ResourceOwnerCondition) GetName() string {
+	return ResourceOwnerConditionName
+}
+
+// Fulfills returns true if the value is the username of the request.
+func (c *ResourceOwnerCondition) Fulfills(value interface{}, r *ladon.Request) bool {
+	owner, ok := value.(string)
+	if !ok || owner == "" {
+		return false
+	}
+
+	username, ok := r.Context["username"].(string)
+
+	return ok && owner == username
+}
+
+// UserAttributeCondition matches an attribute of the authenticated user, a field of the user's `Extend`
+// data. The attribute must be one of values when values are given, otherwise it must be equal to the value
+// given in the request context, e.g. the department of the requested resource.
+// An attribute holding a list matches when any of its elements matches.
+type UserAttributeCondition struct {
+	Attribute string   `json:"attribute"`
+	Values    []string `json:"values,omitempty"`
+}
+
+// GetName returns the condition's name.
+func (c *UserAttributeCondition) GetName() string {
+	return UserAttributeConditionName
+}
+
+// Fulfills returns true if the attribute of the user matches.
+func (c *UserAttributeCondition) Fulfills(value interface{}, r *ladon.Request) bool {
+	attributes, ok := r.Context[ContextKeyUserAttributes].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	attribute, ok := attributes[c.Attribute]
+	if !ok {
+		return false
+	}
+
+	expected := c.Values
+	if len(expected) == 0 {
+		if value == nil {
+			return false
+		}
+
+		expected = attributeValues(value)
+	}
+
+	for _, have := range attributeValues(attribute) {
+		for _, want := range expected {
+			if have == want {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Validate checks that the attribute is given.
+func (c *UserAttributeCondition) Validate() error {
+	if c.Attribute == "" {
+		return fmt.Errorf("attribute is required")
+	}
+
+	return nil
+}
+
+// attributeValues returns the string forms of a scalar attribute or the elements of a list attribute.
+func attributeValues(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			values = append(values, fmt.Sprint(e))
+		}
+
+		return values
+	case []string:
+		return v
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
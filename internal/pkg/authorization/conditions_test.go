@@ -0,0 +1,132 @@
+package authorization
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ory/ladon"
+	"github.com/stretchr/testify/assert"
+)
+
+// setNow fixes the current time of the conditions during the test.
+func setNow(t *testing.T, value string) {
+	t.Helper()
+
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = time.Now })
+}
+
+func TestTimeWindowCondition_Fulfills(t *testing.T) {
+	office := &TimeWindowCondition{Start: "09:00", End: "17:00"}
+	night := &TimeWindowCondition{Start: "22:00", End: "06:00"}
+
+	tests := []struct {
+		name      string
+		condition *TimeWindowCondition
+		now       string
+		want      bool
+	}{
+		{"before the window", office, "2026-10-19T08:59:00Z", false},
+		{"start is inclusive", office, "2026-10-19T09:00:00Z", true},
+		{"within the window", office, "2026-10-19T16:59:00Z", true},
+		{"end is exclusive", office, "2026-10-19T17:00:00Z", false},
+		{"spans midnight, before midnight", night, "2026-10-19T23:30:00Z", true},
+		{"spans midnight, at midnight", night, "2026-10-20T00:00:00Z", true},
+		{"spans midnight, after midnight", night, "2026-10-20T05:59:00Z", true},
+		{"spans midnight, end is exclusive", night, "2026-10-20T06:00:00Z", false},
+		{"spans midnight, outside", night, "2026-10-19T12:00:00Z", false},
+		{"whole day", &TimeWindowCondition{}, "2026-10-19T03:00:00Z", true},
+		{"matching weekday", &TimeWindowCondition{Weekdays: []string{"mon", "Tuesday"}}, "2026-10-19T10:00:00Z", true},
+		{"other weekday", &TimeWindowCondition{Weekdays: []string{"Tuesday"}}, "2026-10-19T10:00:00Z", false},
+		{
+			name:      "window in the timezone",
+			condition: &TimeWindowCondition{Start: "09:00", End: "17:00", Timezone: "Asia/Shanghai"},
+			now:       "2026-10-19T02:00:00Z",
+			want:      true,
+		},
+		{
+			name:      "weekday in the timezone",
+			condition: &TimeWindowCondition{Weekdays: []string{"Tue"}, Timezone: "Asia/Shanghai"},
+			now:       "2026-10-19T20:00:00Z",
+			want:      true,
+		},
+		{"invalid window", &TimeWindowCondition{Start: "9am", End: "17:00"}, "2026-10-19T10:00:00Z", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setNow(t, tt.now)
+
+			assert.Equal(t, tt.want, tt.condition.Fulfills(nil, &ladon.Request{}))
+		})
+	}
+}
+
+func TestDateRangeCondition_Fulfills(t *testing.T) {
+	january := &DateRangeCondition{NotBefore: "2026-01-01", NotAfter: "2026-01-31"}
+
+	tests := []struct {
+		name      string
+		condition *DateRangeCondition
+		now       string
+		want      bool
+	}{
+		{"before the first date", january, "2025-12-31T23:59:59Z", false},
+		{"first date is inclusive", january, "2026-01-01T00:00:00Z", true},
+		{"last date is inclusive", january, "2026-01-31T23:59:59Z", true},
+		{"after the last date", january, "2026-02-01T00:00:00Z", false},
+		{
+			name:      "dates in the timezone",
+			condition: &DateRangeCondition{NotAfter: "2026-01-31", Timezone: "Asia/Shanghai"},
+			now:       "2026-01-31T16:00:00Z",
+			want:      false,
+		},
+		{
+			name:      "last date in the timezone is inclusive",
+			condition: &DateRangeCondition{NotAfter: "2026-01-31", Timezone: "Asia/Shanghai"},
+			now:       "2026-01-31T15:59:59Z",
+			want:      true,
+		},
+		{"time bound is inclusive", &DateRangeCondition{NotAfter: "2026-01-31T12:00:00Z"}, "2026-01-31T12:00:00Z", true},
+		{"after the time bound", &DateRangeCondition{NotAfter: "2026-01-31T12:00:00Z"}, "2026-01-31T12:00:01Z", false},
+		{"unlimited", &DateRangeCondition{}, "2026-10-19T00:00:00Z", true},
+		{"invalid date", &DateRangeCondition{NotBefore: "01/01/2026"}, "2026-10-19T00:00:00Z", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setNow(t, tt.now)
+
+			assert.Equal(t, tt.want, tt.condition.Fulfills(nil, &ladon.Request{}))
+		})
+	}
+}
+
+func Test_hasTimeConditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions ladon.Conditions
+		want       bool
+	}{
+		{"no conditions", nil, false},
+		{"other conditions", ladon.Conditions{"owner": &ResourceOwnerCondition{}}, false},
+		{"time window", ladon.Conditions{"owner": &ResourceOwnerCondition{}, "hours": &TimeWindowCondition{}}, true},
+		{"date range", ladon.Conditions{"validity": &DateRangeCondition{}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies := ladon.Policies{
+				&ladon.DefaultPolicy{ID: "plain"},
+				&ladon.DefaultPolicy{ID: "conditional", Conditions: tt.conditions},
+			}
+
+			assert.Equal(t, tt.want, hasTimeConditions(policies))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package authorization
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/ory/ladon"
+)
+
+// Decision is a cached authorization decision. The policies and deciders are kept so that a cached
+// decision is audited the same way as a computed one.
+type Decision struct {
+	Response *Response
+	Policies ladon.Policies
+	Deciders ladon.Policies
+}
+
+// DecisionCache caches authorization decisions. The generation changes whenever the policies are reloaded,
+// a decision is only served for the generation it is computed in.
+type DecisionCache interface {
+	Generation() uint64
+	GetDecision(generation uint64, key string) (*Decision, bool)
+	SetDecision(generation uint64, key string, decision *Decision)
+}
+
+// DecisionKey returns the canonical hash of the subject, resource, action and context of a request.
+// It returns false when the request can not be encoded, such requests are not cached.
+func DecisionKey(r *ladon.Request) (string, bool) {
+	// encoding/json 对 map 的 key 排序，相同的请求得到相同的编码
+	data, err := json.Marshal(struct {
+		Subject  string        `json:"s"`
+		Resource string        `json:"r"`
+		Action   string        `json:"a"`
+		Context  ladon.Context `json:"c"`
+	}{r.Subject, r.Resource, r.Action, r.Context})
+	if err != nil {
+		return "", false
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), true
+}
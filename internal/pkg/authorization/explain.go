@@ -0,0 +1,157 @@
+package authorization
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ory/ladon"
+)
+
+// ExplainResponse is the authorization response with the explanation of the decision.
+type ExplainResponse struct {
+	*Response
+
+	Explanation *Explanation `json:"explanation"`
+}
+
+// Explanation describes how the candidate policies are evaluated for a request.
+type Explanation struct {
+	// Candidates are the policies considered for the request, in the order they are evaluated.
+	Candidates []*PolicyExplanation `json:"candidates"`
+	// Deciders are the ids of the policies which decided the outcome. It is the deny policy when the request
+	// is forcefully denied, the allow policies when it is allowed, and empty when no policy matches.
+	Deciders []string `json:"deciders"`
+	// Error is set when the candidates can not be evaluated.
+	Error string `json:"error,omitempty"`
+}
+
+// PolicyExplanation describes how one candidate policy matches the request.
+type PolicyExplanation struct {
+	ID               string   `json:"id"`
+	Description      string   `json:"description,omitempty"`
+	Effect           string   `json:"effect"`
+	ActionMatched    bool     `json:"actionMatched"`
+	SubjectMatched   bool     `json:"subjectMatched"`
+	ResourceMatched  bool     `json:"resourceMatched"`
+	ConditionsPassed bool     `json:"conditionsPassed"`
+	FailedConditions []string `json:"failedConditions,omitempty"`
+	Decider          bool     `json:"decider"`
+	// Reason tells why the policy applies or not.
+	Reason string `json:"reason"`
+}
+
+// explain evaluates the candidate policies the same way as ladon.Ladon.DoPoliciesAllow, but checks every
+// policy against all of action, subject, resource and conditions so that the reason of a mismatch is complete.
+func explain(r *ladon.Request, policies ladon.Policies) *Explanation {
+	explanation := &Explanation{
+		Candidates: make([]*PolicyExplanation, 0, len(policies)),
+		Deciders:   []string{},
+	}
+
+	var allows []*PolicyExplanation
+	var denied bool
+
+	for _, p := range policies {
+		pe, err := explainPolicy(r, p)
+		if err != nil {
+			explanation.Error = err.Error()
+
+			return explanation
+		}
+
+		explanation.Candidates = append(explanation.Candidates, pe)
+		if !pe.matched() {
+			continue
+		}
+
+		if denied {
+			pe.Reason = fmt.Sprintf("policy matches the request, but it is already denied by policy %s", explanation.Deciders[0])
+
+			continue
+		}
+
+		// ladon 遇到第一条匹配的 deny 策略就会拒绝请求，之前匹配的 allow 策略不再生效
+		if !p.AllowAccess() {
+			denied = true
+			pe.Decider = true
+			pe.Reason = "policy matches the request and forcefully denies it"
+			explanation.Deciders = []string{pe.ID}
+
+			for _, allow := range allows {
+				allow.Decider = false
+				allow.Reason = fmt.Sprintf("policy allows the request, but policy %s forcefully denies it", pe.ID)
+			}
+
+			continue
+		}
+
+		pe.Decider = true
+		pe.Reason = "policy matches the request and allows it"
+		explanation.Deciders = append(explanation.Deciders, pe.ID)
+		allows = append(allows, pe)
+	}
+
+	return explanation
+}
+
+func explainPolicy(r *ladon.Request, p ladon.Policy) (*PolicyExplanation, error) {
+	var err error
+
+	pe := &PolicyExplanation{
+		ID:          p.GetID(),
+		Description: p.GetDescription(),
+		Effect:      p.GetEffect(),
+	}
+
+	if pe.ActionMatched, err = ladon.DefaultMatcher.Matches(p, p.GetActions(), r.Action); err != nil {
+		return nil, fmt.Errorf("match actions of policy %s failed: %w", pe.ID, err)
+	}
+
+	if pe.SubjectMatched, err = ladon.DefaultMatcher.Matches(p, p.GetSubjects(), r.Subject); err != nil {
+		return nil, fmt.Errorf("match subjects of policy %s failed: %w", pe.ID, err)
+	}
+
+	if pe.ResourceMatched, err = ladon.DefaultMatcher.Matches(p, p.GetResources(), r.Resource); err != nil {
+		return nil, fmt.Errorf("match resources of policy %s failed: %w", pe.ID, err)
+	}
+
+	for key, condition := range p.GetConditions() {
+		if !condition.Fulfills(r.Context[key], r) {
+			pe.FailedConditions = append(pe.FailedConditions, fmt.Sprintf("%s(%s)", key, condition.GetName()))
+		}
+	}
+	sort.Strings(pe.FailedConditions)
+	pe.ConditionsPassed = len(pe.FailedConditions) == 0
+
+	if !pe.matched() {
+		pe.Reason = pe.mismatchReason()
+	}
+
+	return pe, nil
+}
+
+func (pe *PolicyExplanation) matched() bool {
+	return pe.ActionMatched && pe.SubjectMatched && pe.ResourceMatched && pe.ConditionsPassed
+}
+
+func (pe *PolicyExplanation) mismatchReason() string {
+	reasons := []string{}
+	if !pe.ActionMatched {
+		reasons = append(reasons, "action does not match")
+	}
+
+	if !pe.SubjectMatched {
+		reasons = append(reasons, "subject does not match")
+	}
+
+	if !pe.ResourceMatched {
+		reasons = append(reasons, "resource does not match")
+	}
+
+	if !pe.ConditionsPassed {
+		reasons = append(reasons, "conditions are not fulfilled: "+strings.Join(pe.FailedConditions, ", "))
+	}
+
+	return strings.Join(reasons, "; ")
+}
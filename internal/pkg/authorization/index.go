@@ -0,0 +1,175 @@
+package authorization
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ory/ladon"
+)
+
+// PolicyIndex indexes the policies of a user by the literal prefixes of their actions, subjects and
+// resources, so that only the policies which could match a request are checked against it.
+// The candidates are always a superset of the matching policies and keep the order of the policies.
+// A PolicyIndex is immutable and safe for concurrent use.
+type PolicyIndex struct {
+	policies  []*ladon.DefaultPolicy
+	actions   *patternTrie
+	subjects  *patternTrie
+	resources *patternTrie
+}
+
+// NewPolicyIndex builds the index of the given policies.
+func NewPolicyIndex(policies []*ladon.DefaultPolicy) *PolicyIndex {
+	idx := &PolicyIndex{
+		policies:  policies,
+		actions:   newPatternTrie(),
+		subjects:  newPatternTrie(),
+		resources: newPatternTrie(),
+	}
+
+	for i, p := range policies {
+		delimiter := p.GetStartDelimiter()
+		for _, action := range p.GetActions() {
+			idx.actions.insert(action, delimiter, i)
+		}
+
+		for _, subject := range p.GetSubjects() {
+			idx.subjects.insert(subject, delimiter, i)
+		}
+
+		for _, resource := range p.GetResources() {
+			idx.resources.insert(resource, delimiter, i)
+		}
+	}
+
+	return idx
+}
+
+// Policies returns all the indexed policies.
+func (idx *PolicyIndex) Policies() []*ladon.DefaultPolicy {
+	return idx.policies
+}
+
+// FindRequestCandidates returns the policies whose actions, subjects and resources could all match the request.
+func (idx *PolicyIndex) FindRequestCandidates(r *ladon.Request) []*ladon.DefaultPolicy {
+	return idx.find(
+		fieldQuery{idx.actions, r.Action},
+		fieldQuery{idx.subjects, r.Subject},
+		fieldQuery{idx.resources, r.Resource},
+	)
+}
+
+// FindPoliciesForSubject returns the policies which could match the subject.
+func (idx *PolicyIndex) FindPoliciesForSubject(subject string) []*ladon.DefaultPolicy {
+	return idx.find(fieldQuery{idx.subjects, subject})
+}
+
+// FindPoliciesForResource returns the policies which could match the resource.
+func (idx *PolicyIndex) FindPoliciesForResource(resource string) []*ladon.DefaultPolicy {
+	return idx.find(fieldQuery{idx.resources, resource})
+}
+
+type fieldQuery struct {
+	trie   *patternTrie
+	needle string
+}
+
+// find returns the policies matched by all the queries.
+func (idx *PolicyIndex) find(queries ...fieldQuery) []*ladon.DefaultPolicy {
+	// hits 记录策略连续命中的字段数，只有命中了前面所有字段的策略才会继续计数，同一字段多次命中只计一次
+	hits := make(map[int]int)
+	for n, q := range queries {
+		q.trie.match(q.needle, func(i int) {
+			if hits[i] == n {
+				hits[i] = n + 1
+			}
+		})
+	}
+
+	matched := make([]int, 0, len(hits))
+	for i, n := range hits {
+		if n == len(queries) {
+			matched = append(matched, i)
+		}
+	}
+
+	sort.Ints(matched)
+
+	policies := make([]*ladon.DefaultPolicy, 0, len(matched))
+	for _, i := range matched {
+		policies = append(policies, idx.policies[i])
+	}
+
+	return policies
+}
+
+// patternTrie is a byte trie of the literal prefixes of ladon patterns. A pattern without regular
+// expressions only matches itself, while a pattern with regular expressions may match any string starting
+// with the literal part before its first start delimiter, since ladon anchors the compiled pattern.
+type patternTrie struct {
+	root *trieNode
+}
+
+type trieNode struct {
+	children map[byte]*trieNode
+	// exact 保存和节点路径完全相同的模式，prefix 保存以节点路径为字面前缀的正则模式
+	exact  []int
+	prefix []int
+}
+
+func newPatternTrie() *patternTrie {
+	return &patternTrie{root: &trieNode{}}
+}
+
+// insert adds the pattern of the i-th policy to the trie.
+func (t *patternTrie) insert(pattern string, delimiter byte, i int) {
+	literal := pattern
+	regexp := false
+	if pos := strings.IndexByte(pattern, delimiter); pos >= 0 {
+		literal = pattern[:pos]
+		regexp = true
+	}
+
+	node := t.root
+	for j := 0; j < len(literal); j++ {
+		if node.children == nil {
+			node.children = make(map[byte]*trieNode)
+		}
+
+		child, ok := node.children[literal[j]]
+		if !ok {
+			child = &trieNode{}
+			node.children[literal[j]] = child
+		}
+
+		node = child
+	}
+
+	if regexp {
+		node.prefix = append(node.prefix, i)
+	} else {
+		node.exact = append(node.exact, i)
+	}
+}
+
+// match calls fn with the policies whose patterns could match the needle, a policy may be reported more than once.
+func (t *patternTrie) match(needle string, fn func(i int)) {
+	node := t.root
+	for j := 0; ; j++ {
+		for _, i := range node.prefix {
+			fn(i)
+		}
+
+		if j == len(needle) {
+			break
+		}
+
+		if node = node.children[needle[j]]; node == nil {
+			return
+		}
+	}
+
+	for _, i := range node.exact {
+		fn(i)
+	}
+}
@@ -0,0 +1,84 @@
+package authorization
+
+import (
+	"testing"
+
+	"github.com/ory/ladon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyIndex_FindRequestCandidates(t *testing.T) {
+	policy := func(id string, subjects, resources, actions []string) *ladon.DefaultPolicy {
+		return &ladon.DefaultPolicy{
+			ID:        id,
+			Effect:    ladon.AllowAccess,
+			Subjects:  subjects,
+			Resources: resources,
+			Actions:   actions,
+		}
+	}
+
+	idx := NewPolicyIndex([]*ladon.DefaultPolicy{
+		policy("exact", []string{"users:alice"}, []string{"articles:1"}, []string{"read"}),
+		policy("regex", []string{"users:<.*>"}, []string{"articles:<[0-9]+>"}, []string{"read", "write"}),
+		policy("delete", []string{"users:alice"}, []string{"articles:1"}, []string{"delete"}),
+		policy("comments", []string{"<.*>"}, []string{"comments:<.*>"}, []string{"read"}),
+		// 同一字段的多个值都命中时只计一次
+		policy("repeated", []string{"users:bob", "users:alice"}, []string{"articles:1"}, []string{"read", "read"}),
+		// 候选策略只按字面前缀过滤，正则是否匹配由 ladon 判断
+		policy("superset", []string{"users:<[0-9]+>"}, []string{"articles:1"}, []string{"read"}),
+	})
+
+	tests := []struct {
+		name    string
+		request *ladon.Request
+		want    []string
+	}{
+		{
+			name:    "exact and regex policies",
+			request: &ladon.Request{Subject: "users:alice", Resource: "articles:1", Action: "read"},
+			want:    []string{"exact", "regex", "repeated", "superset"},
+		},
+		{
+			name:    "only the regex action matches",
+			request: &ladon.Request{Subject: "users:alice", Resource: "articles:1", Action: "write"},
+			want:    []string{"regex"},
+		},
+		{
+			name:    "exact action",
+			request: &ladon.Request{Subject: "users:alice", Resource: "articles:1", Action: "delete"},
+			want:    []string{"delete"},
+		},
+		{
+			name:    "regex matching any subject",
+			request: &ladon.Request{Subject: "users:bob", Resource: "comments:9", Action: "read"},
+			want:    []string{"comments"},
+		},
+		{
+			name:    "regex resource of another user",
+			request: &ladon.Request{Subject: "users:carol", Resource: "articles:2", Action: "write"},
+			want:    []string{"regex"},
+		},
+		{
+			name:    "literal prefix does not match",
+			request: &ladon.Request{Subject: "users:alice", Resource: "articles", Action: "read"},
+			want:    []string{},
+		},
+		{
+			name:    "no policy for the action",
+			request: &ladon.Request{Subject: "users:alice", Resource: "articles:1", Action: "share"},
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []string{}
+			for _, p := range idx.FindRequestCandidates(tt.request) {
+				ids = append(ids, p.ID)
+			}
+
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
@@ -0,0 +1,405 @@
+package authorization
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ory/ladon"
+	"github.com/ory/ladon/compiler"
+)
+
+// 策略静态检查：在策略生效之前发现有问题的策略，而不是在线上得到意外的授权结果。
+// iam-apiserver 创建和更新策略时检查，iam-policy-lint 命令行检查策略文件。
+
+// LintSeverity is the severity of the findings of a lint rule.
+type LintSeverity string
+
+// Severities of the lint rules, the findings of the rules turned off are not reported.
+const (
+	LintSeverityError   LintSeverity = "error"
+	LintSeverityWarning LintSeverity = "warning"
+	LintSeverityOff     LintSeverity = "off"
+)
+
+// Names of the lint rules.
+const (
+	// LintRuleInvalidRegex reports the `<...>` templates which are not valid regular expressions.
+	LintRuleInvalidRegex = "invalid-regex"
+	// LintRuleShadowedAllow reports the allow policies which never apply, since every request they match is
+	// forcefully denied by broader deny policies without conditions.
+	LintRuleShadowedAllow = "shadowed-allow"
+	// LintRuleBroadWildcard reports the allow policies matching any resource or any action.
+	LintRuleBroadWildcard = "broad-wildcard"
+	// LintRuleDuplicatePolicy reports the policies with the id or the content of a previous policy.
+	LintRuleDuplicatePolicy = "duplicate-policy"
+	// LintRuleUnknownCondition reports the conditions of a type unknown to iam-authz-server.
+	LintRuleUnknownCondition = "unknown-condition"
+	// LintRuleInvalidCondition reports the conditions whose options are invalid.
+	LintRuleInvalidCondition = "invalid-condition"
+)
+
+// DefaultLintSeverities returns the default severity of every lint rule.
+func DefaultLintSeverities() map[string]LintSeverity {
+	return map[string]LintSeverity{
+		LintRuleInvalidRegex:     LintSeverityError,
+		LintRuleShadowedAllow:    LintSeverityWarning,
+		LintRuleBroadWildcard:    LintSeverityWarning,
+		LintRuleDuplicatePolicy:  LintSeverityWarning,
+		LintRuleUnknownCondition: LintSeverityError,
+		LintRuleInvalidCondition: LintSeverityError,
+	}
+}
+
+// matchAllTemplates are the templates matching any value.
+var matchAllTemplates = map[string]bool{"<.*>": true, "<.+>": true, "<.*?>": true, "<.+?>": true}
+
+// LintPolicy is a policy to lint. The conditions are kept undecoded, so that the ones of unknown types
+// are reported instead of failing the decoding of the whole policy.
+type LintPolicy struct {
+	ID          string                   `json:"id"`
+	Description string                   `json:"description,omitempty"`
+	Subjects    []string                 `json:"subjects"`
+	Effect      string                   `json:"effect"`
+	Resources   []string                 `json:"resources"`
+	Actions     []string                 `json:"actions"`
+	Conditions  map[string]LintCondition `json:"conditions,omitempty"`
+}
+
+// LintCondition is an undecoded ladon condition.
+type LintCondition struct {
+	Type    string          `json:"type"`
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// NewLintPolicy converts a ladon policy to the policy to lint.
+func NewLintPolicy(policy *ladon.DefaultPolicy) (*LintPolicy, error) {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	lp := &LintPolicy{}
+	if err := json.Unmarshal(data, lp); err != nil {
+		return nil, err
+	}
+
+	return lp, nil
+}
+
+// LintFinding is an issue found in a policy.
+type LintFinding struct {
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	// Policy is the id of the policy with the issue.
+	Policy string `json:"policy"`
+	// Field is the field of the policy with the issue, like `resources` or `conditions.owner`.
+	Field string `json:"field,omitempty"`
+	// Related are the ids of the other policies involved, like the deny policies shadowing an allow policy.
+	Related []string `json:"related,omitempty"`
+	Message string   `json:"message"`
+}
+
+// String returns the finding as a line of text.
+func (f *LintFinding) String() string {
+	location := f.Policy
+	if f.Field != "" {
+		location += "." + f.Field
+	}
+
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Severity, location, f.Message, f.Rule)
+}
+
+// LintResult is the outcome of linting a set of policies.
+type LintResult struct {
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Findings []*LintFinding `json:"findings"`
+}
+
+// Involving returns the findings of the policy, and the findings of other policies related to it.
+func (r *LintResult) Involving(id string) *LintResult {
+	result := &LintResult{Findings: []*LintFinding{}}
+	for _, f := range r.Findings {
+		if f.Policy == id || contains(f.Related, id) {
+			result.add(f)
+		}
+	}
+
+	return result
+}
+
+func (r *LintResult) add(f *LintFinding) {
+	switch f.Severity {
+	case LintSeverityError:
+		r.Errors++
+	case LintSeverityWarning:
+		r.Warnings++
+	case LintSeverityOff:
+		return
+	}
+
+	r.Findings = append(r.Findings, f)
+}
+
+// ValidateLintSeverities checks the severities overriding the defaults, keyed by the rule names.
+func ValidateLintSeverities(severities map[string]string) error {
+	defaults := DefaultLintSeverities()
+	for rule, severity := range severities {
+		if _, ok := defaults[rule]; !ok {
+			return fmt.Errorf("unknown lint rule `%s`", rule)
+		}
+
+		switch LintSeverity(severity) {
+		case LintSeverityError, LintSeverityWarning, LintSeverityOff:
+		default:
+			return fmt.Errorf("invalid severity `%s` of lint rule `%s`, must be one of error, warning and off",
+				severity, rule)
+		}
+	}
+
+	return nil
+}
+
+// Linter analyzes the policy set of a user.
+type Linter struct {
+	severities map[string]LintSeverity
+}
+
+// NewLinter creates a linter with the severities overriding the defaults, keyed by the rule names.
+func NewLinter(severities map[string]string) (*Linter, error) {
+	if err := ValidateLintSeverities(severities); err != nil {
+		return nil, err
+	}
+
+	l := &Linter{severities: DefaultLintSeverities()}
+	for rule, severity := range severities {
+		l.severities[rule] = LintSeverity(severity)
+	}
+
+	return l, nil
+}
+
+// Lint checks the policies, the findings are ordered by the policies.
+func (l *Linter) Lint(policies []*LintPolicy) *LintResult {
+	result := &LintResult{Findings: []*LintFinding{}}
+	report := func(rule string, p *LintPolicy, field string, related []string, format string, args ...interface{}) {
+		result.add(&LintFinding{
+			Rule:     rule,
+			Severity: l.severities[rule],
+			Policy:   p.ID,
+			Field:    field,
+			Related:  related,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	ids := make(map[string]bool, len(policies))
+	contents := make(map[string]string, len(policies))
+
+	for _, p := range policies {
+		for _, field := range []struct {
+			name   string
+			values []string
+		}{{"subjects", p.Subjects}, {"resources", p.Resources}, {"actions", p.Actions}} {
+			for _, value := range field.values {
+				if !isTemplate(value) {
+					continue
+				}
+
+				if _, err := compiler.CompileRegex(value, '<', '>'); err != nil {
+					report(LintRuleInvalidRegex, p, field.name, nil, "invalid template `%s`: %s", value, err.Error())
+				}
+			}
+		}
+
+		if p.Effect == ladon.AllowAccess {
+			if containsMatchAll(p.Resources) {
+				report(LintRuleBroadWildcard, p, "resources", nil, "allow policy matches any resource")
+			}
+
+			if containsMatchAll(p.Actions) {
+				report(LintRuleBroadWildcard, p, "actions", nil, "allow policy matches any action")
+			}
+
+			if denies := shadowingDenies(p, policies); len(denies) > 0 {
+				report(LintRuleShadowedAllow, p, "", denies,
+					"allow policy never applies, the requests it matches are denied by %s", strings.Join(denies, ", "))
+			}
+		}
+
+		for _, key := range sortedConditionKeys(p.Conditions) {
+			condition := p.Conditions[key]
+			field := "conditions." + key
+
+			factory, ok := ladon.ConditionFactories[condition.Type]
+			if !ok {
+				report(LintRuleUnknownCondition, p, field, nil, "unknown condition type `%s`", condition.Type)
+
+				continue
+			}
+
+			if err := validateLintCondition(factory(), condition.Options); err != nil {
+				report(LintRuleInvalidCondition, p, field, nil, "invalid options of %s: %s", condition.Type, err.Error())
+			}
+		}
+
+		if ids[p.ID] {
+			report(LintRuleDuplicatePolicy, p, "id", nil, "id is used by a previous policy")
+		}
+		ids[p.ID] = true
+
+		key := contentKey(p)
+		if previous, ok := contents[key]; ok {
+			report(LintRuleDuplicatePolicy, p, "", []string{previous}, "policy has the same content as %s", previous)
+		} else {
+			contents[key] = p.ID
+		}
+	}
+
+	return result
+}
+
+// shadowingDenies returns the ids of the deny policies without conditions which cover all the subjects,
+// resources and actions of the allow policy.
+func shadowingDenies(allow *LintPolicy, policies []*LintPolicy) []string {
+	var denies []string
+	for _, p := range policies {
+		if p.Effect != ladon.DenyAccess || len(p.Conditions) > 0 {
+			continue
+		}
+
+		if coversAll(p.Subjects, allow.Subjects) && coversAll(p.Resources, allow.Resources) &&
+			coversAll(p.Actions, allow.Actions) {
+			denies = append(denies, p.ID)
+		}
+	}
+
+	return denies
+}
+
+// coversAll reports whether every value of `values` is matched by one of the patterns. It is conservative,
+// a template is only known to match a plain value, any value when it matches all, or an equal template.
+func coversAll(patterns, values []string) bool {
+	if len(values) == 0 {
+		return false
+	}
+
+	for _, value := range values {
+		covered := false
+		for _, pattern := range patterns {
+			if covers(pattern, value) {
+				covered = true
+
+				break
+			}
+		}
+
+		if !covered {
+			return false
+		}
+	}
+
+	return true
+}
+
+func covers(pattern, value string) bool {
+	if pattern == value || matchAllTemplates[pattern] {
+		return true
+	}
+
+	if !isTemplate(pattern) || isTemplate(value) {
+		return false
+	}
+
+	reg, err := compiler.CompileRegex(pattern, '<', '>')
+	if err != nil {
+		return false
+	}
+
+	matched, err := reg.MatchString(value)
+
+	return err == nil && matched
+}
+
+// validateLintCondition decodes the options into the condition and checks them.
+func validateLintCondition(condition ladon.Condition, options json.RawMessage) error {
+	if len(options) > 0 {
+		if err := json.Unmarshal(options, condition); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := condition.(conditionValidator); ok {
+		return v.Validate()
+	}
+
+	return nil
+}
+
+// contentKey identifies what a policy does, regardless of its id, description and the order of the values.
+func contentKey(p *LintPolicy) string {
+	conditions := make(map[string]LintCondition, len(p.Conditions))
+	for key, condition := range p.Conditions {
+		var options bytes.Buffer
+		if err := json.Compact(&options, condition.Options); err == nil {
+			condition.Options = options.Bytes()
+		}
+
+		conditions[key] = condition
+	}
+
+	data, _ := json.Marshal(struct {
+		Effect     string
+		Subjects   []string
+		Resources  []string
+		Actions    []string
+		Conditions map[string]LintCondition
+	}{p.Effect, sortedCopy(p.Subjects), sortedCopy(p.Resources), sortedCopy(p.Actions), conditions})
+
+	return string(data)
+}
+
+func isTemplate(value string) bool {
+	return strings.Contains(value, "<")
+}
+
+func containsMatchAll(values []string) bool {
+	for _, value := range values {
+		if matchAllTemplates[value] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedConditionKeys(conditions map[string]LintCondition) []string {
+	keys := make([]string, 0, len(conditions))
+	for key := range conditions {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedCopy(values []string) []string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,46 @@
+package authorization
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// LintOptions contains configuration items related to the policy linter.
+type LintOptions struct {
+	// 按规则名覆盖默认的严重级别：error 阻止创建策略，warning 只提示，off 不检查
+	Severity map[string]string `json:"severity" mapstructure:"severity"`
+}
+
+// NewLintOptions creates a LintOptions object with default parameters.
+func NewLintOptions() *LintOptions {
+	return &LintOptions{
+		Severity: map[string]string{},
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *LintOptions) Validate() []error {
+	if o == nil {
+		return nil
+	}
+
+	if err := ValidateLintSeverities(o.Severity); err != nil {
+		return []error{fmt.Errorf("--policy-lint.severity: %w", err)}
+	}
+
+	return nil
+}
+
+// AddFlags adds flags related to the policy linter to the specified FlagSet.
+func (o *LintOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.StringToStringVar(&o.Severity, "policy-lint.severity", o.Severity, ""+
+		"Severity of the policy lint rules, overriding the defaults, e.g. broad-wildcard=error,duplicate-policy=off. "+
+		"The rules are invalid-regex, shadowed-allow, broad-wildcard, duplicate-policy, unknown-condition and "+
+		"invalid-condition, the severities are error, warning and off. Policies with error findings are rejected.")
+}
@@ -0,0 +1,198 @@
+package authorization
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ory/ladon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_covers(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		value   string
+		want    bool
+	}{
+		{"equal plain value", "articles", "articles", true},
+		{"different plain value", "articles", "comments", false},
+		{"match all template", "<.*>", "articles:1", true},
+		{"match all template covers a template", "<.+>", "articles:<.*>", true},
+		{"equal template", "articles:<[0-9]+>", "articles:<[0-9]+>", true},
+		{"template matches the plain value", "articles:<[0-9]+>", "articles:42", true},
+		{"template does not match the plain value", "articles:<[0-9]+>", "articles:abc", false},
+		{"template is not known to cover another template", "articles:<.*>", "articles:<[0-9]+>", false},
+		{"plain value does not cover a template", "articles:1", "articles:<.*>", false},
+		{"invalid template", "articles:<[>", "articles:1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, covers(tt.pattern, tt.value))
+		})
+	}
+}
+
+func Test_shadowingDenies(t *testing.T) {
+	allow := &LintPolicy{
+		ID:        "allow",
+		Effect:    ladon.AllowAccess,
+		Subjects:  []string{"users:alice", "users:bob"},
+		Resources: []string{"articles:1"},
+		Actions:   []string{"read"},
+	}
+
+	tests := []struct {
+		name string
+		deny *LintPolicy
+		want []string
+	}{
+		{
+			name: "deny covers every value",
+			deny: &LintPolicy{
+				ID: "deny", Effect: ladon.DenyAccess,
+				Subjects: []string{"users:<.*>"}, Resources: []string{"articles:<[0-9]+>"}, Actions: []string{"read"},
+			},
+			want: []string{"deny"},
+		},
+		{
+			name: "deny misses a subject",
+			deny: &LintPolicy{
+				ID: "deny", Effect: ladon.DenyAccess,
+				Subjects: []string{"users:alice"}, Resources: []string{"<.*>"}, Actions: []string{"<.*>"},
+			},
+		},
+		{
+			name: "deny misses the action",
+			deny: &LintPolicy{
+				ID: "deny", Effect: ladon.DenyAccess,
+				Subjects: []string{"<.*>"}, Resources: []string{"<.*>"}, Actions: []string{"write"},
+			},
+		},
+		{
+			name: "deny with conditions",
+			deny: &LintPolicy{
+				ID: "deny", Effect: ladon.DenyAccess,
+				Subjects: []string{"<.*>"}, Resources: []string{"<.*>"}, Actions: []string{"<.*>"},
+				Conditions: map[string]LintCondition{"owner": {Type: "EqualsSubjectCondition"}},
+			},
+		},
+		{
+			name: "allow does not shadow",
+			deny: &LintPolicy{
+				ID: "other", Effect: ladon.AllowAccess,
+				Subjects: []string{"<.*>"}, Resources: []string{"<.*>"}, Actions: []string{"<.*>"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, shadowingDenies(allow, []*LintPolicy{allow, tt.deny}))
+		})
+	}
+}
+
+func Test_contentKey(t *testing.T) {
+	base := &LintPolicy{
+		ID:        "base",
+		Effect:    ladon.AllowAccess,
+		Subjects:  []string{"users:alice", "users:bob"},
+		Resources: []string{"articles:1"},
+		Actions:   []string{"read", "write"},
+		Conditions: map[string]LintCondition{
+			"ip": {Type: "CIDRCondition", Options: json.RawMessage(`{"cidr":"10.0.0.0/8"}`)},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *LintPolicy)
+		same   bool
+	}{
+		{
+			name:   "different id and description",
+			modify: func(p *LintPolicy) { p.ID, p.Description = "other", "another policy" },
+			same:   true,
+		},
+		{
+			name: "values in another order",
+			modify: func(p *LintPolicy) {
+				p.Subjects, p.Actions = []string{"users:bob", "users:alice"}, []string{"write", "read"}
+			},
+			same: true,
+		},
+		{
+			name: "condition options with whitespaces",
+			modify: func(p *LintPolicy) {
+				p.Conditions = map[string]LintCondition{
+					"ip": {Type: "CIDRCondition", Options: json.RawMessage(`{ "cidr": "10.0.0.0/8" }`)},
+				}
+			},
+			same: true,
+		},
+		{
+			name:   "different effect",
+			modify: func(p *LintPolicy) { p.Effect = ladon.DenyAccess },
+		},
+		{
+			name:   "different actions",
+			modify: func(p *LintPolicy) { p.Actions = []string{"read"} },
+		},
+		{
+			name: "different condition options",
+			modify: func(p *LintPolicy) {
+				p.Conditions = map[string]LintCondition{
+					"ip": {Type: "CIDRCondition", Options: json.RawMessage(`{"cidr":"192.168.0.0/16"}`)},
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := *base
+			tt.modify(&other)
+
+			assert.Equal(t, tt.same, contentKey(base) == contentKey(&other))
+		})
+	}
+}
+
+func TestLinter_Lint_Duplicates(t *testing.T) {
+	linter, err := NewLinter(nil)
+	require.NoError(t, err)
+
+	policy := func(id string, actions ...string) *LintPolicy {
+		return &LintPolicy{
+			ID: id, Effect: ladon.AllowAccess,
+			Subjects: []string{"users:alice"}, Resources: []string{"articles:1"}, Actions: actions,
+		}
+	}
+
+	result := linter.Lint([]*LintPolicy{
+		policy("first", "read", "write"),
+		policy("second", "write", "read"),
+		policy("first", "delete"),
+	})
+
+	assert.Equal(t, []*LintFinding{
+		{
+			Rule:     LintRuleDuplicatePolicy,
+			Severity: LintSeverityWarning,
+			Policy:   "second",
+			Related:  []string{"first"},
+			Message:  "policy has the same content as first",
+		},
+		{
+			Rule:     LintRuleDuplicatePolicy,
+			Severity: LintSeverityWarning,
+			Policy:   "first",
+			Field:    "id",
+			Message:  "id is used by a previous policy",
+		},
+	}, result.Findings)
+	assert.Equal(t, 2, result.Warnings)
+}
@@ -12,6 +12,11 @@ import (
 // AuditLogger outputs and cache information about granting or rejecting policies.
 type AuditLogger struct {
 	client AuthorizationInterface
+
+	// 最近一次审计的策略和决策策略，用于缓存授权结果
+	logged   bool
+	policies ladon.Policies
+	deciders ladon.Policies
 }
 
 // NewAuditLogger creates a AuditLogger with default parameters.
@@ -23,12 +28,23 @@ func NewAuditLogger(client AuthorizationInterface) *AuditLogger {
 
 // LogRejectedAccessRequest write rejected subject access to log.
 func (a *AuditLogger) LogRejectedAccessRequest(r *ladon.Request, p ladon.Policies, d ladon.Policies) {
+	a.record(p, d)
 	a.client.LogRejectedAccessRequest(r, p, d)
 	log.Debug("subject access review rejected", log.Any("request", r), log.Any("deciders", d))
 }
 
 // LogGrantedAccessRequest write granted subject access to log.
 func (a *AuditLogger) LogGrantedAccessRequest(r *ladon.Request, p ladon.Policies, d ladon.Policies) {
+	a.record(p, d)
 	a.client.LogGrantedAccessRequest(r, p, d)
 	log.Debug("subject access review granted", log.Any("request", r), log.Any("deciders", d))
 }
+
+func (a *AuditLogger) record(p ladon.Policies, d ladon.Policies) {
+	a.logged, a.policies, a.deciders = true, p, d
+}
+
+// reset forgets the last audited request.
+func (a *AuditLogger) reset() {
+	a.logged, a.policies, a.deciders = false, nil, nil
+}
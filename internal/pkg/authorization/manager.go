@@ -0,0 +1,131 @@
+package authorization
+
+import (
+	"github.com/marmotedu/errors"
+	"github.com/ory/ladon"
+)
+
+// PolicyManager is a mysql implementation for Manager to storage
+// policies persistently. A PolicyManager is scoped to one user, the policies of the other users
+// are never returned.
+type PolicyManager struct {
+	client   AuthorizationInterface
+	username string
+}
+
+// NewPolicyManager initializes a new PolicyManager of the user for given apimachinery api
+// client.
+func NewPolicyManager(client AuthorizationInterface, username string) ladon.Manager {
+	return &PolicyManager{
+		client:   client,
+		username: username,
+	}
+}
+
+// Create persists the policy.
+func (*PolicyManager) Create(policy ladon.Policy) error {
+	return nil
+}
+
+// Update updates an existing policy.
+func (*PolicyManager) Update(policy ladon.Policy) error {
+	return nil
+}
+
+// Get retrieves a policy.
+func (*PolicyManager) Get(id string) (ladon.Policy, error) {
+	return nil, nil
+}
+
+// Delete removes a policy.
+func (*PolicyManager) Delete(id string) error {
+	return nil
+}
+
+// GetAll retrieves all policies.
+func (*PolicyManager) GetAll(limit, offset int64) (ladon.Policies, error) {
+	return nil, nil
+}
+
+// FindRequestCandidates returns candidates that could match the request object. It either returns
+// a set that exactly matches the request, or a superset of it. If an error occurs, it returns nil and
+// the error.
+func (m *PolicyManager) FindRequestCandidates(r *ladon.Request) (ladon.Policies, error) {
+	var (
+		policies []*ladon.DefaultPolicy
+		err      error
+	)
+
+	// 优先使用索引预先过滤候选策略，否则返回用户的全部策略
+	if finder, ok := m.client.(PolicyFinder); ok {
+		policies, err = finder.FindRequestCandidates(m.username, r)
+	} else {
+		policies, err = m.client.List(m.username)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "list policies failed")
+	}
+
+	return toPolicies(policies), nil
+}
+
+// FindPoliciesForSubject returns policies of the user that could match the subject. It either returns
+// a set of policies that applies to the subject, or a superset of it.
+// If an error occurs, it returns nil and the error.
+func (m *PolicyManager) FindPoliciesForSubject(subject string) (ladon.Policies, error) {
+	var (
+		policies []*ladon.DefaultPolicy
+		err      error
+	)
+
+	if finder, ok := m.client.(PolicyFinder); ok {
+		policies, err = finder.FindPoliciesForSubject(m.username, subject)
+	} else if policies, err = m.client.List(m.username); err == nil {
+		policies = NewPolicyIndex(policies).FindPoliciesForSubject(subject)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "find policies for subject failed")
+	}
+
+	return toPolicies(policies), nil
+}
+
+// FindPoliciesForResource returns policies of the user that could match the resource. It either returns
+// a set of policies that apply to the resource, or a superset of it.
+// If an error occurs, it returns nil and the error.
+func (m *PolicyManager) FindPoliciesForResource(resource string) (ladon.Policies, error) {
+	var (
+		policies []*ladon.DefaultPolicy
+		err      error
+	)
+
+	if finder, ok := m.client.(PolicyFinder); ok {
+		policies, err = finder.FindPoliciesForResource(m.username, resource)
+	} else if policies, err = m.client.List(m.username); err == nil {
+		policies = NewPolicyIndex(policies).FindPoliciesForResource(resource)
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "find policies for resource failed")
+	}
+
+	return toPolicies(policies), nil
+}
+
+// RequestUsername returns the user of the request, which the policies are looked up for.
+func RequestUsername(r *ladon.Request) string {
+	username, _ := r.Context["username"].(string)
+
+	return username
+}
+
+func toPolicies(policies []*ladon.DefaultPolicy) ladon.Policies {
+	ret := make([]ladon.Policy, 0, len(policies))
+	for _, policy := range policies {
+		ret = append(ret, policy)
+	}
+
+	return ret
+}
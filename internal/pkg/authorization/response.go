@@ -0,0 +1,45 @@
+package authorization
+
+import (
+	authzv1 "github.com/marmotedu/api/authz/v1"
+	"github.com/ory/ladon"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+)
+
+// Response is the authorization response. A denied response carries a machine-readable reason code
+// defined in `internal/pkg/code`, and the id of the policy which decided it if there is one.
+type Response struct {
+	authzv1.Response
+
+	ReasonCode int    `json:"reasonCode,omitempty"`
+	Policy     string `json:"policy,omitempty"`
+}
+
+// DenialReason returns the reason code of a request denied by ladon and the id of the deciding policy,
+// given the candidate policies and the deciders ladon audited:
+// the deny policy for code.ErrDeniedByPolicy, the first allow policy which matches the request except for
+// its conditions for code.ErrDeniedConditionFailed, and no policy for code.ErrDeniedNoPolicy.
+func DenialReason(r *ladon.Request, policies ladon.Policies, deciders ladon.Policies) (int, string) {
+	// ladon 遇到 deny 策略时，deny 策略是最后一个 decider
+	if len(deciders) > 0 && !deciders[len(deciders)-1].AllowAccess() {
+		return code.ErrDeniedByPolicy, deciders[len(deciders)-1].GetID()
+	}
+
+	for _, p := range policies {
+		if !p.AllowAccess() {
+			continue
+		}
+
+		pe, err := explainPolicy(r, p)
+		if err != nil {
+			continue
+		}
+
+		if pe.ActionMatched && pe.SubjectMatched && pe.ResourceMatched && !pe.ConditionsPassed {
+			return code.ErrDeniedConditionFailed, pe.ID
+		}
+	}
+
+	return code.ErrDeniedNoPolicy, ""
+}
@@ -4,7 +4,7 @@
 
 package authorization
 
-//go:generate mockgen -destination mock_authorization.go -package authorization github.com/marmotedu/iam/internal/authzserver/authorization AuthorizationInterface
+//go:generate mockgen -destination mock_authorization.go -package authorization github.com/nico612/iam-demo/internal/pkg/authorization AuthorizationInterface
 
 import (
 	"github.com/ory/ladon"
@@ -23,3 +23,11 @@ type AuthorizationInterface interface {
 	LogRejectedAccessRequest(request *ladon.Request, pool ladon.Policies, deciders ladon.Policies)
 	LogGrantedAccessRequest(request *ladon.Request, pool ladon.Policies, deciders ladon.Policies)
 }
+
+// PolicyFinder is optionally implemented by an AuthorizationInterface which looks up policies with
+// prebuilt indexes. Each method returns the policies of the user that could match, or a superset of them.
+type PolicyFinder interface {
+	FindRequestCandidates(username string, r *ladon.Request) ([]*ladon.DefaultPolicy, error)
+	FindPoliciesForSubject(username, subject string) ([]*ladon.DefaultPolicy, error)
+	FindPoliciesForResource(username, resource string) ([]*ladon.DefaultPolicy, error)
+}
@@ -24,4 +24,37 @@ const (
 const (
 	// ErrPolicyNotFound - 404: Policy not found.
 	ErrPolicyNotFound int = iota + 110201
+
+	// ErrPolicyAlreadyExist - 400: Policy already exist.
+	ErrPolicyAlreadyExist
+
+	// ErrPolicyRevisionNotFound - 404: Policy revision not found.
+	ErrPolicyRevisionNotFound
+
+	// ErrPolicyLintFailed - 400: Policy failed the lint checks.
+	ErrPolicyLintFailed
+)
+
+// iam-apiserver: api key errors.
+const (
+	// ErrAPIKeyNotFound - 404: API key not found.
+	ErrAPIKeyNotFound int = iota + 110301
+)
+
+// iam-apiserver: session errors.
+const (
+	// ErrSessionNotFound - 404: Session not found.
+	ErrSessionNotFound int = iota + 110401
+)
+
+// iam-apiserver: tenant errors.
+const (
+	// ErrTenantNotFound - 404: Tenant not found.
+	ErrTenantNotFound int = iota + 110501
+
+	// ErrTenantAlreadyExist - 400: Tenant already exist.
+	ErrTenantAlreadyExist
+
+	// ErrTenantNotEmpty - 400: Tenant still has users.
+	ErrTenantNotEmpty
 )
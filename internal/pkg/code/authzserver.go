@@ -0,0 +1,25 @@
+package code
+
+//go:generate codegen -type=int
+
+// iam-authz-server: authorization errors, they are returned as the reason codes of denied
+// authorization responses.
+const (
+	// ErrDeniedNoPolicy - 403: No policy allows the request.
+	ErrDeniedNoPolicy int = iota + 120001
+
+	// ErrDeniedByPolicy - 403: Request is forcefully denied by a policy.
+	ErrDeniedByPolicy
+
+	// ErrDeniedConditionFailed - 403: Conditions of the matching policy are not fulfilled.
+	ErrDeniedConditionFailed
+
+	// ErrAuthorizationFailed - 500: Policies could not be evaluated.
+	ErrAuthorizationFailed
+)
+
+// iam-authz-server: rate limit errors.
+const (
+	// ErrRateLimitExceeded - 429: Too many requests.
+	ErrRateLimitExceeded int = iota + 120101
+)
@@ -8,6 +8,24 @@ package code
 
 // init register error codes defines in this source code to `github.com/marmotedu/errors`
 func init() {
+	register(ErrUserNotFound, 404, "User not found")
+	register(ErrUserAlreadyExist, 400, "User already exist")
+	register(ErrReachMaxCount, 400, "Secret reach the max count")
+	register(ErrSecretNotFound, 404, "Secret not found")
+	register(ErrPolicyNotFound, 404, "Policy not found")
+	register(ErrPolicyAlreadyExist, 400, "Policy already exist")
+	register(ErrPolicyRevisionNotFound, 404, "Policy revision not found")
+	register(ErrPolicyLintFailed, 400, "Policy failed the lint checks")
+	register(ErrAPIKeyNotFound, 404, "API key not found")
+	register(ErrSessionNotFound, 404, "Session not found")
+	register(ErrTenantNotFound, 404, "Tenant not found")
+	register(ErrTenantAlreadyExist, 400, "Tenant already exist")
+	register(ErrTenantNotEmpty, 400, "Tenant still has users")
+	register(ErrDeniedNoPolicy, 403, "No policy allows the request")
+	register(ErrDeniedByPolicy, 403, "Request is forcefully denied by a policy")
+	register(ErrDeniedConditionFailed, 403, "Conditions of the matching policy are not fulfilled")
+	register(ErrAuthorizationFailed, 500, "Policies could not be evaluated")
+	register(ErrRateLimitExceeded, 429, "Too many requests")
 	register(ErrSuccess, 200, "OK")
 	register(ErrUnknown, 500, "Internal server error")
 	register(ErrBind, 400, "Error occurred while binding the request body to the struct")
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+)
+
+// apikey 策略：该策略实现了不透明 API Key 认证，适用于不方便走登录流程的服务间调用
+// 认证格式为：Authorization: ApiKey XXXX  或者 X-API-Key: XXXX
+
+const (
+	// APIKeyHeader defines the header used to carry an api key.
+	APIKeyHeader = "X-API-Key"
+
+	// APIKeyScheme defines the authorization scheme used to carry an api key.
+	APIKeyScheme = "ApiKey"
+)
+
+// APIKey contains the basic information of an api key.
+type APIKey struct {
+	ID       string
+	Username string
+	Expires  int64
+}
+
+// APIKeyStrategy defines opaque api key authentication strategy.
+// The presented key is looked up by the `get` function which is expected to compare
+// hashed values only, so that plaintext keys never need to be stored.
+type APIKeyStrategy struct {
+	get func(token string) (APIKey, error)
+}
+
+var (
+	_ middleware.AuthStrategy = &APIKeyStrategy{}
+	_ Authenticator           = &APIKeyStrategy{}
+)
+
+// NewAPIKeyStrategy create api key strategy with lookup function.
+func NewAPIKeyStrategy(get func(token string) (APIKey, error)) APIKeyStrategy {
+	return APIKeyStrategy{get: get}
+}
+
+// AuthFunc defines api key strategy as the gin authentication middleware.
+func (a APIKeyStrategy) AuthFunc() gin.HandlerFunc {
+	return authFunc(StrategyAPIKey, a)
+}
+
+// Authenticate authenticates the request with the api key it carries.
+func (a APIKeyStrategy) Authenticate(c *gin.Context) (string, error) {
+	token := apiKeyFromRequest(c.Request)
+	if token == "" {
+		return "", ErrNotApplicable
+	}
+
+	key, err := a.get(token)
+	if err != nil {
+		return "", errors.WithCode(code.ErrSignatureInvalid, "invalid API key.")
+	}
+
+	if KeyExpired(key.Expires) {
+		tm := time.Unix(key.Expires, 0).Format("2006-01-02 15:04:05")
+
+		return "", errors.WithCode(code.ErrExpired, "expired at: %s", tm)
+	}
+
+	return key.Username, nil
+}
+
+// apiKeyFromRequest returns the api key carried by `Authorization: ApiKey XXX` or `X-API-Key: XXX`.
+func apiKeyFromRequest(r *http.Request) string {
+	if token := r.Header.Get(APIKeyHeader); token != "" {
+		return token
+	}
+
+	auth := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(auth) == 2 && auth[0] == APIKeyScheme {
+		return strings.TrimSpace(auth[1])
+	}
+
+	return ""
+}
@@ -3,7 +3,6 @@ package auth
 import (
 	"encoding/base64"
 	"github.com/gin-gonic/gin"
-	"github.com/marmotedu/component-base/pkg/core"
 	"github.com/marmotedu/errors"
 	"github.com/nico612/iam-demo/internal/pkg/code"
 	"github.com/nico612/iam-demo/internal/pkg/middleware"
@@ -17,39 +16,34 @@ type BasicStrategy struct {
 	compare func(username string, password string) bool
 }
 
-var _ middleware.AuthStrategy = &BasicStrategy{}
+var (
+	_ middleware.AuthStrategy = &BasicStrategy{}
+	_ Authenticator           = &BasicStrategy{}
+)
 
 // NewBasicStrategy create basic strategy with compare function.
 func NewBasicStrategy(compare func(username string, password string) bool) BasicStrategy {
 	return BasicStrategy{compare: compare}
 }
 
+// AuthFunc defines basic strategy as the gin authentication middleware.
 func (b BasicStrategy) AuthFunc() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		auth := strings.SplitN(c.Request.Header.Get("Authorization"), " ", 2)
-		if len(auth) != 2 || auth[0] != "Basic" {
-			core.WriteResponse(
-				c,
-				errors.WithCode(code.ErrSignatureInvalid, "Authorization header format is wrong."),
-				nil,
-			)
-			c.Abort()
-			return
-		}
-
-		payload, _ := base64.StdEncoding.DecodeString(auth[1])
-		pair := strings.SplitN(string(payload), ":", 2)
-
-		if len(pair) != 2 || !b.compare(pair[0], pair[1]) { // 验证账号密码
-			core.WriteResponse(
-				c,
-				errors.WithCode(code.ErrSignatureInvalid, "Authorization header format is wrong."),
-				nil,
-			)
-			c.Abort()
-			return
-		}
-		c.Set(middleware.UsernameKey, pair[0])
-		c.Next()
+	return authFunc(StrategyBasic, b)
+}
+
+// Authenticate authenticates the request with `Authorization: Basic XXX` header.
+func (b BasicStrategy) Authenticate(c *gin.Context) (string, error) {
+	auth := strings.SplitN(c.Request.Header.Get("Authorization"), " ", 2)
+	if len(auth) != 2 || auth[0] != "Basic" {
+		return "", ErrNotApplicable
 	}
+
+	payload, _ := base64.StdEncoding.DecodeString(auth[1])
+	pair := strings.SplitN(string(payload), ":", 2)
+
+	if len(pair) != 2 || !b.compare(pair[0], pair[1]) { // 验证账号密码
+		return "", errors.WithCode(code.ErrSignatureInvalid, "Authorization header format is wrong.")
+	}
+
+	return pair[0], nil
 }
@@ -7,7 +7,6 @@ import (
 	"github.com/gin-gonic/gin"
 	jwt "github.com/golang-jwt/jwt/v4"
 
-	"github.com/marmotedu/component-base/pkg/core"
 	"github.com/marmotedu/errors"
 	"github.com/nico612/iam-demo/internal/pkg/code"
 	"github.com/nico612/iam-demo/internal/pkg/middleware"
@@ -35,70 +34,97 @@ type CacheStrategy struct {
 	get func(kid string) (Secret, error)
 }
 
-var _ middleware.AuthStrategy = &CacheStrategy{}
+var (
+	_ middleware.AuthStrategy = &CacheStrategy{}
+	_ Authenticator           = &CacheStrategy{}
+)
 
 func NewCacheStrategy(get func(kid string) (Secret, error)) CacheStrategy {
 	return CacheStrategy{get: get}
 }
 
+// AuthFunc defines cache strategy as the gin authentication middleware.
 func (cache CacheStrategy) AuthFunc() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		header := c.Request.Header.Get("Authorization")
-		if len(header) == 0 {
-			core.WriteResponse(c, errors.WithCode(code.ErrMissingHeader, "Authorization header cannot be empty."), nil)
-			return
-		}
+	return authFunc(StrategyCache, cache)
+}
 
-		var rawJWT string
-		// Parse the header to get the token part.
-		fmt.Sscanf(header, "Bearer %s", &rawJWT)
+// Authenticate authenticates the request with `Authorization: Bearer XXX` header, the token must be
+// signed with a secret which carries its id in the `kid` header.
+func (cache CacheStrategy) Authenticate(c *gin.Context) (string, error) {
+	header := c.Request.Header.Get("Authorization")
+	if len(header) == 0 {
+		return "", ErrNotApplicable
+	}
 
-		// Use own validation logic, see below
-		var secret Secret
+	var rawJWT string
+	// Parse the header to get the token part.
+	if _, err := fmt.Sscanf(header, "Bearer %s", &rawJWT); err != nil {
+		return "", ErrNotApplicable
+	}
 
-		claims := &jwt.MapClaims{}
+	// tokens without `kid` are not signed by a secret, leave them to the other strategies.
+	if token, _, err := jwt.NewParser().ParseUnverified(rawJWT, &jwt.MapClaims{}); err == nil {
+		if _, ok := token.Header["kid"]; !ok {
+			return "", ErrNotApplicable
+		}
+	}
 
-		// Verify the token
-		parsedT, err := jwt.ParseWithClaims(rawJWT, claims, func(token *jwt.Token) (interface{}, error) {
+	secret, err := cache.ParseToken(rawJWT)
+	if err != nil {
+		return "", err
+	}
 
-			// Validate the alg is HMAC signature
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
+	c.Set(middleware.SecretIDKey, secret.ID)
 
-			kid, ok := token.Header["kid"].(string)
-			if !ok {
-				return nil, ErrMissingKID
-			}
+	return secret.Username, nil
+}
+
+// ParseToken verifies the raw jwt token and returns the secret it is signed with.
+// It returns a `github.com/marmotedu/errors.withCode` error when the token is invalid or expired.
+func (cache CacheStrategy) ParseToken(rawJWT string) (Secret, error) {
+	// Use own validation logic, see below
+	var secret Secret
 
-			var err error
-			secret, err = cache.get(kid)
-			if err != nil {
-				return nil, ErrMissingSecret
-			}
+	claims := &jwt.MapClaims{}
 
-			return []byte(secret.Key), nil
-		})
+	// Verify the token
+	parsedT, err := jwt.ParseWithClaims(rawJWT, claims, func(token *jwt.Token) (interface{}, error) {
+
+		// Validate the alg is HMAC signature
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
 
-		if err != nil || !parsedT.Valid {
-			core.WriteResponse(c, errors.WithCode(code.ErrSignatureInvalid, err.Error()), nil)
-			c.Abort()
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrMissingKID
+		}
 
-			return
+		var err error
+		secret, err = cache.get(kid)
+		if err != nil {
+			return nil, ErrMissingSecret
 		}
 
-		if KeyExpired(secret.Expires) { // 如果 Key 过期
-			tm := time.Unix(secret.Expires, 0).Format("2006-01-02 15:04:05")
-			core.WriteResponse(c, errors.WithCode(code.ErrExpired, "expired at: %s", tm), nil)
-			c.Abort()
+		return []byte(secret.Key), nil
+	})
 
-			return
+	if err != nil || !parsedT.Valid {
+		msg := "token is invalid"
+		if err != nil {
+			msg = err.Error()
 		}
 
-		c.Set(middleware.UsernameKey, secret.Username)
-		c.Next()
+		return Secret{}, errors.WithCode(code.ErrSignatureInvalid, msg)
+	}
+
+	if KeyExpired(secret.Expires) { // 如果 Key 过期
+		tm := time.Unix(secret.Expires, 0).Format("2006-01-02 15:04:05")
+
+		return Secret{}, errors.WithCode(code.ErrExpired, "expired at: %s", tm)
 	}
 
+	return secret, nil
 }
 
 // KeyExpired checks if a key has expired, if the value of user.SessionState.Expires is 0, it will be ignored.
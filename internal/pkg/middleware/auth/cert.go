@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+	genericoptions "github.com/nico612/iam-demo/internal/pkg/options"
+)
+
+// cert 策略：该策略实现了客户端证书(mTLS)认证，证书由 HTTPS/gRPC 服务根据 client-ca-file 校验，
+// 认证时只使用配置的一个证书字段(CN 或者一种 SAN)作为身份，并通过显式配置的映射找到对应的用户，适用于机器客户端
+
+// CertStrategy defines client certificate authentication strategy.
+type CertStrategy struct {
+	field  string
+	lookup func(identity string) (string, error)
+}
+
+var (
+	_ middleware.AuthStrategy = &CertStrategy{}
+	_ Authenticator           = &CertStrategy{}
+)
+
+// NewCertStrategy create client certificate strategy, the identity is read from the certificate field
+// (see genericoptions.CertIdentityCommonName and the like) and mapped to an iam username with lookup.
+func NewCertStrategy(field string, lookup func(identity string) (string, error)) CertStrategy {
+	return CertStrategy{field: field, lookup: lookup}
+}
+
+// AuthFunc defines client certificate strategy as the gin authentication middleware.
+func (s CertStrategy) AuthFunc() gin.HandlerFunc {
+	return authFunc(StrategyMTLS, s)
+}
+
+// Authenticate authenticates the request with the verified client certificate of the tls connection.
+func (s CertStrategy) Authenticate(c *gin.Context) (string, error) {
+	return s.authenticate(c.Request.TLS)
+}
+
+func (s CertStrategy) authenticate(state *tls.ConnectionState) (string, error) {
+	cert := verifiedClientCert(state)
+	if cert == nil {
+		return "", ErrNotApplicable
+	}
+
+	identity, err := CertIdentity(cert, s.field)
+	if err != nil {
+		return "", errors.WithCode(code.ErrSignatureInvalid, err.Error())
+	}
+
+	username, err := s.lookup(identity)
+	if err != nil {
+		return "", errors.WithCode(code.ErrSignatureInvalid, "client certificate `%s` does not map to any user.", identity)
+	}
+
+	return username, nil
+}
+
+// CertIdentity returns the identity carried by the field of a client certificate. The field must hold
+// exactly one value, so that a certificate can not choose among several identities.
+func CertIdentity(cert *x509.Certificate, field string) (string, error) {
+	var values []string
+	switch field {
+	case genericoptions.CertIdentityCommonName:
+		if cert.Subject.CommonName != "" {
+			values = append(values, cert.Subject.CommonName)
+		}
+	case genericoptions.CertIdentityDNS:
+		values = cert.DNSNames
+	case genericoptions.CertIdentityEmail:
+		values = cert.EmailAddresses
+	case genericoptions.CertIdentityURI:
+		for _, uri := range cert.URIs {
+			values = append(values, uri.String())
+		}
+	default:
+		return "", errors.Errorf("unsupported client certificate identity field `%s`", field)
+	}
+
+	if len(values) != 1 {
+		return "", errors.Errorf("client certificate must carry exactly one `%s` identity, found %d", field, len(values))
+	}
+
+	return values[0], nil
+}
+
+// NewCertUserMapping returns a lookup function of NewCertStrategy which maps the identities with the
+// explicit mapping, the identities not listed are rejected.
+func NewCertUserMapping(mapping map[string]string) func(identity string) (string, error) {
+	return func(identity string) (string, error) {
+		username, ok := mapping[identity]
+		if !ok {
+			return "", errors.Errorf("client certificate identity `%s` is not mapped", identity)
+		}
+
+		return username, nil
+	}
+}
+
+// verifiedClientCert returns the leaf client certificate verified by the tls server, nil if there is none.
+// Only verified chains are used, the raw peer certificates are never trusted.
+func verifiedClientCert(state *tls.ConnectionState) *x509.Certificate {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return state.VerifiedChains[0][0]
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+	"github.com/nico612/iam-demo/internal/pkg/middleware"
+)
+
+// chain 策略：按配置的顺序依次执行多个认证策略，每个策略可以 接受(accept)、拒绝(reject) 或者 跳过(pass)，
+// 策略跳过时交给下一个策略处理，所有策略都跳过时认证失败
+
+// Names of the strategies which can take part in an authentication chain.
+const (
+	StrategyBasic  = "basic"
+	StrategyJWT    = "jwt"
+	StrategyCache  = "cache"
+	StrategyAPIKey = "apikey"
+	StrategyMTLS   = "mtls"
+)
+
+// ErrNotApplicable is returned by an Authenticator when the request does not carry credentials
+// it understands, so the next strategy of the chain is tried.
+var ErrNotApplicable = errors.New("authentication strategy is not applicable")
+
+// Authenticator is implemented by the strategies which can take part in a ChainStrategy.
+// Authenticate returns the authenticated username to accept the request, ErrNotApplicable to pass,
+// or a `github.com/marmotedu/errors.withCode` error to reject it.
+type Authenticator interface {
+	Authenticate(c *gin.Context) (string, error)
+}
+
+// NamedAuthenticator binds an Authenticator with the name it is configured with.
+type NamedAuthenticator struct {
+	Name          string
+	Authenticator Authenticator
+}
+
+// ChainStrategy defines an ordered chain of authentication strategies.
+type ChainStrategy struct {
+	authenticators []NamedAuthenticator
+}
+
+var _ middleware.AuthStrategy = &ChainStrategy{}
+
+// NewChainStrategy create chain strategy with the given authenticators, which are tried in order.
+func NewChainStrategy(authenticators ...NamedAuthenticator) ChainStrategy {
+	return ChainStrategy{authenticators: authenticators}
+}
+
+// AuthFunc defines chain strategy as the gin authentication middleware.
+func (chain ChainStrategy) AuthFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, a := range chain.authenticators {
+			username, err := a.Authenticator.Authenticate(c)
+			if errors.Is(err, ErrNotApplicable) {
+				continue
+			}
+
+			if err != nil {
+				core.WriteResponse(c, err, nil)
+				c.Abort()
+
+				return
+			}
+
+			c.Set(middleware.UsernameKey, username)
+			c.Set(middleware.AuthStrategyKey, a.Name)
+			c.Next()
+
+			return
+		}
+
+		core.WriteResponse(c, errors.WithCode(code.ErrMissingHeader, "no supported credentials found in request."), nil)
+		c.Abort()
+	}
+}
+
+// authFunc returns the gin authentication middleware of a single authenticator.
+func authFunc(name string, a Authenticator) gin.HandlerFunc {
+	return NewChainStrategy(NamedAuthenticator{Name: name, Authenticator: a}).AuthFunc()
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strconv"
+
+	"github.com/marmotedu/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// secretIDContextKey is the context key of the id of the secret which signed the grpc call.
+type secretIDContextKey struct{}
+
+// UnaryServerInterceptor defines cache strategy as the grpc authentication interceptor.
+// The jwt token is passed with `authorization: Bearer XXX` metadata, the authenticated username
+// is saved into the context with key `log.KeyUsername`, and the secret id is read with SecretIDFromContext.
+func (cache CacheStrategy) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		var rawJWT string
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+		}
+
+		if _, err := fmt.Sscanf(values[0], "Bearer %s", &rawJWT); err != nil {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata must be `Bearer XXX`")
+		}
+
+		secret, err := cache.ParseToken(rawJWT)
+		if err != nil {
+			return nil, unauthenticated(errors.ParseCoder(err))
+		}
+
+		ctx = context.WithValue(ctx, secretIDContextKey{}, secret.ID)
+
+		// log.L 使用相同的 key 读取用户名
+		return handler(context.WithValue(ctx, log.KeyUsername, secret.Username), req) // nolint: staticcheck
+	}
+}
+
+// UnaryServerInterceptor defines client certificate strategy as the grpc authentication interceptor. The calls
+// without a verified client certificate are passed to next, they are rejected when next is nil. The authenticated
+// username is saved into the context with key `log.KeyUsername`.
+func (s CertStrategy) UnaryServerInterceptor(next grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		var state *tls.ConnectionState
+		if p, ok := peer.FromContext(ctx); ok {
+			if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+				state = &tlsInfo.State
+			}
+		}
+
+		username, err := s.authenticate(state)
+		if errors.Is(err, ErrNotApplicable) {
+			if next == nil {
+				return nil, status.Error(codes.Unauthenticated, "client certificate is required")
+			}
+
+			return next(ctx, req, info, handler)
+		}
+
+		if err != nil {
+			return nil, unauthenticated(errors.ParseCoder(err))
+		}
+
+		return handler(context.WithValue(ctx, log.KeyUsername, username), req) // nolint: staticcheck
+	}
+}
+
+// UsernameFromContext returns the username saved by UnaryServerInterceptor.
+func UsernameFromContext(ctx context.Context) string {
+	username, _ := ctx.Value(log.KeyUsername).(string)
+
+	return username
+}
+
+// SecretIDFromContext returns the id of the secret saved by the cache strategy interceptor, it is empty
+// when the call is authenticated with a client certificate.
+func SecretIDFromContext(ctx context.Context) string {
+	secretID, _ := ctx.Value(secretIDContextKey{}).(string)
+
+	return secretID
+}
+
+// unauthenticated returns the Unauthenticated status of an authentication error, the error code is attached
+// as the reason of an ErrorInfo detail so that clients can tell an expired secret from an invalid token.
+func unauthenticated(coder errors.Coder) error {
+	st := status.New(codes.Unauthenticated, coder.String())
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: strconv.Itoa(coder.Code()),
+		Domain: "iam.authz",
+	}); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
@@ -1,8 +1,13 @@
 package auth
 
 import (
+	"encoding/json"
+
 	ginjwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
 	"github.com/nico612/iam-demo/internal/pkg/middleware"
 )
 
@@ -11,19 +16,112 @@ import (
 // AuthzAudience defines the value of jwt audience field.
 const AuthzAudience = "iam.authz.marmotedu.com"
 
+// SessionClaim defines the jwt claim which carries the id of the login session.
+const SessionClaim = "sid"
+
 // JWTStrategy defines jwt bearer authentication strategy.
 type JWTStrategy struct {
 	ginjwt.GinJWTMiddleware
+	validateSession func(c *gin.Context, username, sid string) error
 }
 
-var _ middleware.AuthStrategy = &JWTStrategy{}
+var (
+	_ middleware.AuthStrategy = &JWTStrategy{}
+	_ Authenticator           = &JWTStrategy{}
+)
 
 // NewJWTStrategy create jwt bearer strategy with GinJWTMiddleware.
 func NewJWTStrategy(gjwt ginjwt.GinJWTMiddleware) JWTStrategy {
-	return JWTStrategy{gjwt}
+	return JWTStrategy{GinJWTMiddleware: gjwt}
+}
+
+// WithSessionValidator returns a copy of the strategy which rejects the tokens whose session,
+// carried by the `sid` claim, is no longer active.
+func (j JWTStrategy) WithSessionValidator(validate func(c *gin.Context, username, sid string) error) JWTStrategy {
+	j.validateSession = validate
+
+	return j
 }
 
 // AuthFunc defines jwt bearer strategy as the gin authentication middleware.
 func (j JWTStrategy) AuthFunc() gin.HandlerFunc {
-	return j.MiddlewareFunc()
+	return authFunc(StrategyJWT, j)
+}
+
+// Authenticate authenticates the request with the jwt token found by the `TokenLookup` of GinJWTMiddleware.
+// It does the same checks as GinJWTMiddleware.MiddlewareFunc, but returns errors instead of writing responses.
+func (j JWTStrategy) Authenticate(c *gin.Context) (string, error) {
+	claims, err := j.GetClaimsFromJWT(c)
+	if err != nil {
+		if isMissingJWT(err) {
+			return "", ErrNotApplicable
+		}
+
+		return "", errors.WithCode(code.ErrTokenInvalid, err.Error())
+	}
+
+	if err := j.checkExpire(claims); err != nil {
+		return "", err
+	}
+
+	c.Set("JWT_PAYLOAD", claims)
+	identity := j.IdentityHandler(c)
+
+	if identity != nil {
+		c.Set(j.IdentityKey, identity)
+	}
+
+	if !j.Authorizator(identity, c) {
+		return "", errors.WithCode(code.ErrPermissionDenied, ginjwt.ErrForbidden.Error())
+	}
+
+	username, ok := identity.(string)
+	if !ok || username == "" {
+		return "", errors.WithCode(code.ErrTokenInvalid, "missing identity in token.")
+	}
+
+	if j.validateSession != nil {
+		sid, _ := claims[SessionClaim].(string)
+		if err := j.validateSession(c, username, sid); err != nil {
+			return "", err
+		}
+	}
+
+	return username, nil
+}
+
+func (j JWTStrategy) checkExpire(claims ginjwt.MapClaims) error {
+	var exp int64
+
+	switch v := claims["exp"].(type) {
+	case nil:
+		return errors.WithCode(code.ErrTokenInvalid, ginjwt.ErrMissingExpField.Error())
+	case float64:
+		exp = int64(v)
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return errors.WithCode(code.ErrTokenInvalid, ginjwt.ErrWrongFormatOfExp.Error())
+		}
+		exp = n
+	default:
+		return errors.WithCode(code.ErrTokenInvalid, ginjwt.ErrWrongFormatOfExp.Error())
+	}
+
+	if exp < j.TimeFunc().Unix() {
+		return errors.WithCode(code.ErrExpired, ginjwt.ErrExpiredToken.Error())
+	}
+
+	return nil
+}
+
+// isMissingJWT reports whether err means that no jwt token is carried by the request.
+func isMissingJWT(err error) bool {
+	switch err {
+	case ginjwt.ErrEmptyAuthHeader, ginjwt.ErrInvalidAuthHeader, ginjwt.ErrEmptyQueryToken,
+		ginjwt.ErrEmptyCookieToken, ginjwt.ErrEmptyParamToken:
+		return true
+	default:
+		return false
+	}
 }
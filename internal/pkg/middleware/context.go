@@ -7,6 +7,12 @@ import (
 
 const UsernameKey = "username"
 
+// SecretIDKey defines the key in gin context which represents the id of the secret that signed the request.
+const SecretIDKey = "secretID"
+
+// AuthStrategyKey defines the key in gin context which represents the name of the strategy that authenticated the request.
+const AuthStrategyKey = "authStrategy"
+
 func Context() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Set(log.KeyRequestID, c.GetString(XRequestIDKey))
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/marmotedu/component-base/pkg/core"
+	"github.com/marmotedu/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/nico612/iam-demo/internal/pkg/code"
+)
+
+// 分布式限流：使用 Redis 滚动窗口记录调用方的请求，多个副本共享同一个窗口。
+// Redis 不可用时不限流。
+
+// rateLimitKeyPrefix prefixes the redis keys of the rolling windows.
+const rateLimitKeyPrefix = "rate-limit-"
+
+// RateLimitQuota limits the number of requests of a caller in a rolling window.
+type RateLimitQuota struct {
+	// Key identifies the caller like `secret:<secret id>`, the requests with the same key share the quota.
+	Key    string
+	Limit  int
+	Window time.Duration
+}
+
+// RollingWindow records the requests in rolling windows, it is implemented by storage.RedisCluster.
+type RollingWindow interface {
+	// SetRollingWindow records a request and returns the number and the members of the requests recorded
+	// before it in the window, the members are the nanosecond timestamps of the requests in time order.
+	SetRollingWindow(key string, per int64, val string, pipeline bool) (int, []interface{})
+}
+
+// RateLimit rejects the request with 429 and a `Retry-After` header when any quota of the caller is exceeded.
+// The rejected requests are recorded as well, so a caller has to slow down to get through.
+func RateLimit(store RollingWindow, quotas func(c *gin.Context) []RateLimitQuota) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if quota, wait := exceededQuota(store, quotas(c)); quota != nil {
+			c.Header("Retry-After", strconv.Itoa(wait))
+			core.WriteResponse(c, errors.WithCode(code.ErrRateLimitExceeded, "rate limit of %s exceeded", quota.Key), nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitUnaryServerInterceptor is the grpc version of RateLimit, it must be chained after the authentication
+// interceptor. The calls exceeding a quota are rejected with ResourceExhausted, the seconds to wait are sent with
+// the `retry-after` header and a RetryInfo detail.
+func RateLimitUnaryServerInterceptor(
+	store RollingWindow,
+	quotas func(ctx context.Context) []RateLimitQuota,
+) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		quota, wait := exceededQuota(store, quotas(ctx))
+		if quota == nil {
+			return handler(ctx, req)
+		}
+
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(wait)))
+
+		st := status.Newf(codes.ResourceExhausted, "rate limit of %s exceeded", quota.Key)
+		if detailed, err := st.WithDetails(&errdetails.RetryInfo{
+			RetryDelay: durationpb.New(time.Duration(wait) * time.Second),
+		}); err == nil {
+			st = detailed
+		}
+
+		return nil, st.Err()
+	}
+}
+
+// exceededQuota records the request in the window of each quota, and returns the first exceeded quota with
+// the seconds to wait, nil if none is exceeded.
+func exceededQuota(store RollingWindow, quotas []RateLimitQuota) (*RateLimitQuota, int) {
+	for i := range quotas {
+		quota := &quotas[i]
+
+		count, values := store.SetRollingWindow(rateLimitKeyPrefix+quota.Key, int64(quota.Window/time.Second), "-1", false)
+		if count < quota.Limit {
+			continue
+		}
+
+		return quota, retryAfter(values, count-quota.Limit, quota.Window)
+	}
+
+	return nil, 0
+}
+
+// retryAfter returns the seconds until the request at index expires from the window, at least 1 second.
+func retryAfter(values []interface{}, index int, window time.Duration) int {
+	if index >= len(values) {
+		return int(window / time.Second)
+	}
+
+	member, _ := values[index].(string)
+	nanos, err := strconv.ParseInt(member, 10, 64)
+	if err != nil {
+		return int(window / time.Second)
+	}
+
+	wait := time.Until(time.Unix(0, nanos).Add(window)).Seconds()
+
+	return int(math.Max(1, math.Ceil(wait)))
+}
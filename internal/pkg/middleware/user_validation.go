@@ -31,8 +31,28 @@ func Validation() gin.HandlerFunc {
 
 					return
 				}
+			case "/v1/users/:name/apikeys", "/v1/users/:name/apikeys/:id",
+				"/v1/users/:name/sessions", "/v1/users/:name/sessions/:id":
+				// 普通用户只能管理自己的 api key 和 session
+				if c.GetString(UsernameKey) != c.Param("name") {
+					core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
+					c.Abort()
+
+					return
+				}
+			case "/v1/tenants", "/v1/tenants/:name", "/v1/tenants/:name/users":
+				// 租户只能由管理员管理
+				core.WriteResponse(c, errors.WithCode(code.ErrPermissionDenied, ""), nil)
+				c.Abort()
+
+				return
 			default:
 			}
+		} else if err := checkTenant(c); err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
 		}
 
 		c.Next()
@@ -54,3 +74,41 @@ func isAdmin(c *gin.Context) error {
 
 	return nil
 }
+
+// checkTenant makes sure an administrator only manages the users of its own tenant. The administrators
+// of the default tenant are the administrators of the whole system, they manage all the tenants.
+// It returns a `github.com/marmotedu/errors.withCode` error.
+func checkTenant(c *gin.Context) error {
+	tenant := store.TenantOf(c.GetString(UsernameKey))
+	if tenant == store.DefaultTenant {
+		return nil
+	}
+
+	denied := errors.WithCode(code.ErrPermissionDenied, "administrator of tenant %s can not manage other tenants", tenant)
+
+	switch c.FullPath() {
+	case "/v1/users":
+		// 批量删除的用户必须都属于本租户，查询由 controller 限定在本租户内
+		for _, username := range c.QueryArray("name") {
+			if store.TenantOf(username) != tenant {
+				return denied
+			}
+		}
+	case "/v1/users/:name", "/v1/users/:name/change-password", "/v1/users/:name/change_password",
+		"/v1/users/:name/apikeys", "/v1/users/:name/apikeys/:id",
+		"/v1/users/:name/sessions", "/v1/users/:name/sessions/:id":
+		if store.TenantOf(c.Param("name")) != tenant {
+			return denied
+		}
+	case "/v1/tenants":
+		return denied
+	case "/v1/tenants/:name", "/v1/tenants/:name/users":
+		// 租户管理员可以查看本租户，并在本租户内创建用户，但不能删除租户
+		if c.Param("name") != tenant || (c.FullPath() == "/v1/tenants/:name" && c.Request.Method != http.MethodGet) {
+			return denied
+		}
+	default:
+	}
+
+	return nil
+}
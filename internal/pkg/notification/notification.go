@@ -0,0 +1,126 @@
+// Package notification implements the signed notifications which iam-apiserver publishes to the redis
+// pub/sub channel to tell iam-authz-server which secrets and policies changed.
+package notification
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marmotedu/component-base/pkg/util/idutil"
+	"github.com/marmotedu/errors"
+
+	"github.com/nico612/iam-demo/pkg/log"
+	"github.com/nico612/iam-demo/pkg/storage"
+)
+
+// NotificationCommand defines a new notification type.
+type NotificationCommand string
+
+// Define Redis pub/sub events.
+const (
+	RedisPubSubChannel                      = "iam.cluster.notifications"
+	NoticePolicyChanged NotificationCommand = "PolicyChanged"
+	NoticeSecretChanged NotificationCommand = "SecretChanged"
+)
+
+// Notification is a type that encodes a message published to a pub sub channel (shared between implementations).
+// Notifications are signed with a shared hmac key, see Sign and Verifier.
+type Notification struct {
+	Command       NotificationCommand `json:"command"`
+	Payload       string              `json:"payload"`
+	Timestamp     int64               `json:"timestamp"`
+	Nonce         string              `json:"nonce"`
+	Signature     string              `json:"signature"`
+	SignatureAlgo crypto.Hash         `json:"algorithm"`
+}
+
+// NotificationPayload carries the entries affected by a notification. PolicyChanged notifications carry
+// the username, SecretChanged notifications carry both the username and the secret id.
+// A notification without payload makes iam-authz-server reload all the secrets and policies.
+type NotificationPayload struct {
+	Username string `json:"username,omitempty"`
+	SecretID string `json:"secretID,omitempty"`
+}
+
+// NewPolicyChangedNotification creates the notification of a change of the policies of the user.
+func NewPolicyChangedNotification(username string) Notification {
+	return newNotification(NoticePolicyChanged, NotificationPayload{Username: username})
+}
+
+// NewSecretChangedNotification creates the notification of a change of the secret of the user.
+func NewSecretChangedNotification(username, secretID string) Notification {
+	return newNotification(NoticeSecretChanged, NotificationPayload{Username: username, SecretID: secretID})
+}
+
+func newNotification(command NotificationCommand, payload NotificationPayload) Notification {
+	data, _ := json.Marshal(payload)
+
+	return Notification{Command: command, Payload: string(data)}
+}
+
+// Sign Notification with HMAC-SHA256 algorithm, the timestamp and nonce are set before signing.
+func (n *Notification) Sign(key []byte) {
+	n.Timestamp = time.Now().Unix()
+	n.Nonce = idutil.GetUUID36("")
+	n.SignatureAlgo = crypto.SHA256
+	n.Signature = hex.EncodeToString(n.mac(key))
+}
+
+// mac returns the HMAC-SHA256 of all the signed fields of the notification.
+func (n *Notification) mac(key []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(strings.Join([]string{
+		string(n.Command),
+		n.Payload,
+		strconv.FormatInt(n.Timestamp, 10),
+		n.Nonce,
+	}, "\n")))
+
+	return h.Sum(nil)
+}
+
+// RedisNotifier will use redis pub/sub channels to send notifications.
+type RedisNotifier struct {
+	store   *storage.RedisCluster
+	channel string
+	key     []byte
+}
+
+// NewRedisNotifier creates a notifier which publishes notifications to the channel,
+// notifications are signed with the given hmac key.
+func NewRedisNotifier(store *storage.RedisCluster, channel string, key string) *RedisNotifier {
+	return &RedisNotifier{store: store, channel: channel, key: []byte(key)}
+}
+
+// Notify will send a notification to a channel.
+func (r *RedisNotifier) Notify(notify interface{}) bool {
+	if n, ok := notify.(Notification); ok {
+		n.Sign(r.key) // 签名消息
+		notify = n
+	}
+
+	toSend, err := json.Marshal(notify)
+	if err != nil {
+		log.Errorf("Problem marshaling notification: %s", err.Error())
+
+		return false
+	}
+
+	log.Debugf("Sending notification: %v", notify)
+
+	// 发送消息到指定的 redis 通道中
+	if err := r.store.Publish(r.channel, string(toSend)); err != nil {
+		if !errors.Is(err, storage.ErrRedisIsDown) {
+			log.Errorf("Could not send notification: %s", err.Error())
+		}
+		return false
+	}
+
+	return true
+}
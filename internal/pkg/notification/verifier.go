@@ -0,0 +1,91 @@
+package notification
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/marmotedu/errors"
+)
+
+// Defined verification errors.
+var (
+	ErrNotificationAlgorithm = errors.New("unsupported notification signature algorithm")
+	ErrNotificationSignature = errors.New("invalid notification signature")
+	ErrNotificationExpired   = errors.New("notification timestamp is out of the replay window")
+	ErrNotificationReplayed  = errors.New("notification has been received before")
+)
+
+// Verifier verifies the hmac signature of the notifications. Notifications signed with any of the keys
+// are accepted, they must be signed within the replay window and can only be accepted once.
+type Verifier struct {
+	keys   [][]byte
+	window time.Duration
+
+	lock sync.Mutex
+	seen map[string]time.Time // 重放窗口内已经接受的 nonce 及其过期时间
+}
+
+// NewVerifier creates a notification verifier with the shared keys and the replay window.
+func NewVerifier(keys []string, window time.Duration) *Verifier {
+	v := &Verifier{
+		keys:   make([][]byte, 0, len(keys)),
+		window: window,
+		seen:   make(map[string]time.Time),
+	}
+
+	for _, key := range keys {
+		v.keys = append(v.keys, []byte(key))
+	}
+
+	return v
+}
+
+// Verify returns an error if the notification is not signed by a known key, out of the replay window,
+// or has been accepted before.
+func (v *Verifier) Verify(n *Notification) error {
+	if n.SignatureAlgo != crypto.SHA256 {
+		return ErrNotificationAlgorithm
+	}
+
+	signature, err := hex.DecodeString(n.Signature)
+	if err != nil || !v.signedByKnownKey(n, signature) {
+		return ErrNotificationSignature
+	}
+
+	now := time.Now()
+	signedAt := time.Unix(n.Timestamp, 0)
+	if signedAt.Before(now.Add(-v.window)) || signedAt.After(now.Add(v.window)) {
+		return ErrNotificationExpired
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	for nonce, expire := range v.seen {
+		if now.After(expire) {
+			delete(v.seen, nonce)
+		}
+	}
+
+	if _, ok := v.seen[n.Nonce]; ok {
+		return ErrNotificationReplayed
+	}
+
+	// 时间戳超出窗口后消息会被直接拒绝，nonce 只需要保留到那时
+	v.seen[n.Nonce] = signedAt.Add(v.window)
+
+	return nil
+}
+
+func (v *Verifier) signedByKnownKey(n *Notification, signature []byte) bool {
+	for _, key := range v.keys {
+		if hmac.Equal(n.mac(key), signature) {
+			return true
+		}
+	}
+
+	return false
+}
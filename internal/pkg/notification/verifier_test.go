@@ -0,0 +1,113 @@
+package notification
+
+import (
+	"crypto"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	const window = time.Minute
+
+	// signedAt signs the notification with key as if it was sent at the given time.
+	signedAt := func(key string, at time.Time) *Notification {
+		n := NewPolicyChangedNotification("admin")
+		n.Sign([]byte(key))
+		n.Timestamp = at.Unix()
+		n.Signature = hex.EncodeToString(n.mac([]byte(key)))
+
+		return &n
+	}
+
+	tests := []struct {
+		name string
+		// prepare returns the notification to verify, it may verify other notifications before.
+		prepare func(t *testing.T, v *Verifier) *Notification
+		wantErr error
+	}{
+		{
+			name: "valid notification",
+			prepare: func(t *testing.T, v *Verifier) *Notification {
+				return signedAt("key", time.Now())
+			},
+		},
+		{
+			name: "signed with a rotated key",
+			prepare: func(t *testing.T, v *Verifier) *Notification {
+				return signedAt("old-key", time.Now())
+			},
+		},
+		{
+			name: "unknown key",
+			prepare: func(t *testing.T, v *Verifier) *Notification {
+				return signedAt("unknown", time.Now())
+			},
+			wantErr: ErrNotificationSignature,
+		},
+		{
+			name: "tampered payload",
+			prepare: func(t *testing.T, v *Verifier) *Notification {
+				n := signedAt("key", time.Now())
+				n.Payload = `{"username":"other"}`
+
+				return n
+			},
+			wantErr: ErrNotificationSignature,
+		},
+		{
+			name: "unsupported algorithm",
+			prepare: func(t *testing.T, v *Verifier) *Notification {
+				n := signedAt("key", time.Now())
+				n.SignatureAlgo = crypto.SHA1
+
+				return n
+			},
+			wantErr: ErrNotificationAlgorithm,
+		},
+		{
+			name: "stale timestamp",
+			prepare: func(t *testing.T, v *Verifier) *Notification {
+				return signedAt("key", time.Now().Add(-2*window))
+			},
+			wantErr: ErrNotificationExpired,
+		},
+		{
+			name: "future timestamp",
+			prepare: func(t *testing.T, v *Verifier) *Notification {
+				return signedAt("key", time.Now().Add(2*window))
+			},
+			wantErr: ErrNotificationExpired,
+		},
+		{
+			name: "replayed notification",
+			prepare: func(t *testing.T, v *Verifier) *Notification {
+				n := signedAt("key", time.Now())
+				assert.NoError(t, v.Verify(n))
+
+				replayed := *n
+
+				return &replayed
+			},
+			wantErr: ErrNotificationReplayed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier([]string{"key", "old-key"}, window)
+			n := tt.prepare(t, v)
+
+			err := v.Verify(n)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
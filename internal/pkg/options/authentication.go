@@ -1,31 +1,129 @@
 package options
 
-import "github.com/spf13/pflag"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Identity fields of a client certificate which can be mapped to a user.
+const (
+	CertIdentityCommonName = "cn"
+	CertIdentityDNS        = "dns"
+	CertIdentityEmail      = "email"
+	CertIdentityURI        = "uri"
+)
 
 // ClientCertAuthenticationOptions provides different options for client cert auth.
 type ClientCertAuthenticationOptions struct {
 	// ClientCA is the certificate bundle for all the signers that you'll recognize for incoming client certificates
 	ClientCA string `json:"client-ca-file" mapstructure:"client-ca-file"`
+
+	// Required set to true means that clients must present a certificate signed by ClientCA,
+	// otherwise the certificate is only verified when the client presents one.
+	Required bool `json:"required"       mapstructure:"required"`
+
+	// Identity is the only field of the certificate used as its identity: cn, dns, email or uri.
+	Identity string `json:"identity"       mapstructure:"identity"`
+
+	// Users maps the certificate identities to usernames with `<identity>=<username>` entries,
+	// the certificates whose identity is not listed are rejected.
+	Users []string `json:"users"          mapstructure:"users"`
 }
 
 // NewClientCertAuthenticationOptions creates a ClientCertAuthenticationOptions object with default parameters.
 func NewClientCertAuthenticationOptions() *ClientCertAuthenticationOptions {
 	return &ClientCertAuthenticationOptions{
 		ClientCA: "",
+		Required: false,
+		Identity: CertIdentityCommonName,
+		Users:    []string{},
+	}
+}
+
+// UserMapping returns the usernames keyed by the certificate identities, the malformed entries
+// are reported by SecureServingOptions.Validate.
+func (o *ClientCertAuthenticationOptions) UserMapping() map[string]string {
+	mapping := make(map[string]string, len(o.Users))
+	for _, entry := range o.Users {
+		identity, username, ok := strings.Cut(entry, "=")
+		if ok {
+			mapping[identity] = username
+		}
+	}
+
+	return mapping
+}
+
+func (o *ClientCertAuthenticationOptions) validate() []error {
+	errs := []error{}
+
+	if o.Required && o.ClientCA == "" {
+		errs = append(errs, fmt.Errorf("--secure.client-cert.client-ca-file is required if --secure.client-cert.required is set"))
+	}
+
+	switch o.Identity {
+	case CertIdentityCommonName, CertIdentityDNS, CertIdentityEmail, CertIdentityURI:
+	default:
+		errs = append(errs, fmt.Errorf("--secure.client-cert.identity `%s` must be cn, dns, email or uri", o.Identity))
+	}
+
+	seen := make(map[string]bool, len(o.Users))
+	for _, entry := range o.Users {
+		identity, username, ok := strings.Cut(entry, "=")
+		if !ok || identity == "" || username == "" {
+			errs = append(errs, fmt.Errorf("--secure.client-cert.users entry `%s` must be <identity>=<username>", entry))
+
+			continue
+		}
+
+		if seen[identity] {
+			errs = append(errs, fmt.Errorf("--secure.client-cert.users has duplicated identity `%s`", identity))
+		}
+		seen[identity] = true
+	}
+
+	return errs
+}
+
+// AuthenticationOptions contains the ordered chain of authentication strategies used by a server.
+type AuthenticationOptions struct {
+	// Strategies are tried in order, the first one accepting or rejecting the request wins.
+	Strategies []string `json:"strategies" mapstructure:"strategies"`
+}
+
+// NewAuthenticationOptions creates a AuthenticationOptions object with the given default strategies.
+func NewAuthenticationOptions(strategies ...string) *AuthenticationOptions {
+	return &AuthenticationOptions{
+		Strategies: strategies,
 	}
 }
 
 // Validate is used to parse and validate the parameters entered by the user at
 // the command line when the program starts.
-func (o *ClientCertAuthenticationOptions) Validate() []error {
-	return []error{}
+func (o *AuthenticationOptions) Validate() []error {
+	errs := []error{}
+
+	if len(o.Strategies) == 0 {
+		errs = append(errs, fmt.Errorf("--authentication.strategies can not be empty"))
+	}
+
+	seen := make(map[string]bool, len(o.Strategies))
+	for _, name := range o.Strategies {
+		if seen[name] {
+			errs = append(errs, fmt.Errorf("--authentication.strategies has duplicated strategy `%s`", name))
+		}
+		seen[name] = true
+	}
+
+	return errs
 }
 
-// AddFlags adds flags related to ClientCertAuthenticationOptions for a specific server to the
+// AddFlags adds flags related to authentication for a specific server to the
 // specified FlagSet.
-func (o *ClientCertAuthenticationOptions) AddFlags(fs *pflag.FlagSet) {
-	fs.StringVar(&o.ClientCA, "client-ca-file", o.ClientCA, ""+
-		"If set, any request presenting a client certificate signed by one of "+
-		"the authorities in the client-ca-file is authenticated with an identity "+
-		"corresponding to the CommonName of the client certificate.")
+func (o *AuthenticationOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringSliceVar(&o.Strategies, "authentication.strategies", o.Strategies, ""+
+		"Ordered list of authentication strategies, supported strategies: basic, jwt, cache, apikey, mtls. "+
+		"Each strategy accepts, rejects or passes the request to the next one.")
 }
@@ -48,6 +48,10 @@ func (s *JwtOptions) Validate() []error {
 		errs = append(errs, fmt.Errorf("--secret-key must larger than 5 and little than 33"))
 	}
 
+	if s.MaxRefresh <= 0 {
+		errs = append(errs, fmt.Errorf("--jwt.max-refresh must be greater than 0"))
+	}
+
 	return errs
 }
 
@@ -63,5 +67,5 @@ func (s *JwtOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&s.Timeout, "jwt.timeout", s.Timeout, "JWT token timeout.")
 
 	fs.DurationVar(&s.MaxRefresh, "jwt.max-refresh", s.MaxRefresh, ""+
-		"This field allows clients to refresh their token until MaxRefresh has passed.")
+		"Lifetime of the refresh tokens, which are rotated every time they are used to refresh an access token.")
 }
@@ -0,0 +1,63 @@
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// NotificationOptions contains configuration items related to the cluster notifications
+// published by iam-apiserver and consumed by iam-authz-server.
+type NotificationOptions struct {
+	// Keys are the shared hmac keys, notifications are signed with the first key and
+	// verified with any of them, which allows to rotate keys without downtime.
+	Keys         []string      `json:"keys"          mapstructure:"keys"`
+	ReplayWindow time.Duration `json:"replay-window" mapstructure:"replay-window"`
+}
+
+// NewNotificationOptions creates a NotificationOptions object with default parameters.
+func NewNotificationOptions() *NotificationOptions {
+	return &NotificationOptions{
+		Keys:         []string{},
+		ReplayWindow: 30 * time.Second,
+	}
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *NotificationOptions) Validate() []error {
+	var errs []error
+
+	if len(o.Keys) == 0 {
+		errs = append(errs, fmt.Errorf("--notification.keys is required to sign and verify notifications"))
+	}
+
+	for i, key := range o.Keys {
+		if len(key) < 32 {
+			errs = append(errs, fmt.Errorf("--notification.keys: key %d must be at least 32 characters", i))
+		}
+	}
+
+	if o.ReplayWindow <= 0 {
+		errs = append(errs, fmt.Errorf("--notification.replay-window must be greater than 0"))
+	}
+
+	return errs
+}
+
+// AddFlags adds flags related to notifications for a specific server to the
+// specified FlagSet.
+func (o *NotificationOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.StringSliceVar(&o.Keys, "notification.keys", o.Keys, ""+
+		"Shared keys used to sign and verify cluster notifications with HMAC-SHA256. Notifications are signed "+
+		"with the first key and verified with any of them, append the new key and move it to the front to rotate.")
+
+	fs.DurationVar(&o.ReplayWindow, "notification.replay-window", o.ReplayWindow, ""+
+		"Notifications older or newer than the window are rejected, and a notification can only be used once "+
+		"within the window.")
+}
@@ -0,0 +1,127 @@
+package options
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// RateLimitOptions contains configuration items related to the quotas of the callers. The quotas are counted
+// in redis rolling windows shared by all the replicas, a limit of 0 means unlimited.
+type RateLimitOptions struct {
+	Window      time.Duration `json:"window"       mapstructure:"window"`
+	SecretLimit int           `json:"secret-limit" mapstructure:"secret-limit"`
+	UserLimit   int           `json:"user-limit"   mapstructure:"user-limit"`
+	// Secrets and Users override the default limits with `<secret id>=<limit>` and `<username>=<limit>`
+	// entries. They are lists rather than maps since viper lowercases the keys of maps, while secret ids
+	// and usernames are case sensitive.
+	Secrets []string `json:"secrets" mapstructure:"secrets"`
+	Users   []string `json:"users"   mapstructure:"users"`
+}
+
+// NewRateLimitOptions creates a RateLimitOptions object with default parameters.
+func NewRateLimitOptions() *RateLimitOptions {
+	return &RateLimitOptions{
+		Window:      time.Minute,
+		SecretLimit: 0,
+		UserLimit:   0,
+		Secrets:     []string{},
+		Users:       []string{},
+	}
+}
+
+// SecretLimits returns the limits keyed by the secret ids, the malformed entries are reported by Validate.
+func (o *RateLimitOptions) SecretLimits() map[string]int {
+	limits, _ := parseLimits("secrets", o.Secrets)
+
+	return limits
+}
+
+// UserLimits returns the limits keyed by the usernames, the malformed entries are reported by Validate.
+func (o *RateLimitOptions) UserLimits() map[string]int {
+	limits, _ := parseLimits("users", o.Users)
+
+	return limits
+}
+
+// parseLimits parses the `<key>=<limit>` entries of the flag --rate-limit.<name>.
+func parseLimits(name string, entries []string) (map[string]int, []error) {
+	limits := make(map[string]int, len(entries))
+
+	var errs []error
+	for _, entry := range entries {
+		key, value, ok := strings.Cut(entry, "=")
+		limit, err := strconv.Atoi(value)
+		if !ok || key == "" || err != nil {
+			errs = append(errs, fmt.Errorf("--rate-limit.%s entry `%s` must be <key>=<limit>", name, entry))
+
+			continue
+		}
+
+		if limit < 0 {
+			errs = append(errs, fmt.Errorf("--rate-limit.%s: limit %v of %s must not be negative", name, limit, key))
+
+			continue
+		}
+
+		if _, ok := limits[key]; ok {
+			errs = append(errs, fmt.Errorf("--rate-limit.%s has duplicated key `%s`", name, key))
+		}
+
+		limits[key] = limit
+	}
+
+	return limits, errs
+}
+
+// Validate is used to parse and validate the parameters entered by the user at
+// the command line when the program starts.
+func (o *RateLimitOptions) Validate() []error {
+	var errs []error
+
+	if o.Window < time.Second || o.Window%time.Second != 0 {
+		errs = append(errs, fmt.Errorf("--rate-limit.window %v must be a whole number of seconds", o.Window))
+	}
+
+	if o.SecretLimit < 0 {
+		errs = append(errs, fmt.Errorf("--rate-limit.secret-limit %v must not be negative", o.SecretLimit))
+	}
+
+	if o.UserLimit < 0 {
+		errs = append(errs, fmt.Errorf("--rate-limit.user-limit %v must not be negative", o.UserLimit))
+	}
+
+	_, secretErrs := parseLimits("secrets", o.Secrets)
+	errs = append(errs, secretErrs...)
+
+	_, userErrs := parseLimits("users", o.Users)
+	errs = append(errs, userErrs...)
+
+	return errs
+}
+
+// AddFlags adds flags related to rate limiting for a specific server to the
+// specified FlagSet.
+func (o *RateLimitOptions) AddFlags(fs *pflag.FlagSet) {
+	if fs == nil {
+		return
+	}
+
+	fs.DurationVar(&o.Window, "rate-limit.window", o.Window, ""+
+		"Length of the rolling window the requests are counted in, a whole number of seconds.")
+
+	fs.IntVar(&o.SecretLimit, "rate-limit.secret-limit", o.SecretLimit, ""+
+		"Maximum number of requests signed with one secret in the window, 0 means unlimited.")
+
+	fs.IntVar(&o.UserLimit, "rate-limit.user-limit", o.UserLimit, ""+
+		"Maximum number of requests of one user in the window, 0 means unlimited.")
+
+	fs.StringSliceVar(&o.Secrets, "rate-limit.secrets", o.Secrets, ""+
+		"Limits of specific secrets overriding --rate-limit.secret-limit, e.g. <secret id>=100.")
+
+	fs.StringSliceVar(&o.Users, "rate-limit.users", o.Users, ""+
+		"Limits of specific users overriding --rate-limit.user-limit, e.g. <username>=1000.")
+}
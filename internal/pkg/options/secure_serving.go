@@ -18,6 +18,8 @@ type SecureServingOptions struct {
 	Required bool
 	// ServerCert is the TLS cert info for serving secure traffic
 	ServerCert GeneratableKeyCert `json:"tls"          mapstructure:"tls"`
+	// ClientCert is used to verify client certificates presented to the HTTPS and gRPC servers.
+	ClientCert ClientCertAuthenticationOptions `json:"client-cert"  mapstructure:"client-cert"`
 	// AdvertiseAddress net.IP
 }
 
@@ -52,6 +54,7 @@ func NewSecureServingOptions() *SecureServingOptions {
 			PairName:      "iam",
 			CertDirectory: "/var/run/iam",
 		},
+		ClientCert: *NewClientCertAuthenticationOptions(),
 	}
 }
 
@@ -65,6 +68,8 @@ func (s *SecureServingOptions) ApplyTo(c *server.Config) error {
 			CertFile: s.ServerCert.CertKey.CertFile,
 			KeyFile:  s.ServerCert.CertKey.KeyFile,
 		},
+		ClientCA:          s.ClientCert.ClientCA,
+		RequireClientCert: s.ClientCert.Required,
 	}
 
 	return nil
@@ -91,6 +96,8 @@ func (s *SecureServingOptions) Validate() []error {
 		errors = append(errors, fmt.Errorf("--secure.bind-port %v must be between 0 and 65535, inclusive. 0 for turning off secure port", s.BindPort))
 	}
 
+	errors = append(errors, s.ClientCert.validate()...)
+
 	return errors
 }
 
@@ -125,6 +132,22 @@ func (s *SecureServingOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.ServerCert.CertKey.KeyFile, "secure.tls.cert-key.private-key-file",
 		s.ServerCert.CertKey.KeyFile, ""+
 			"File containing the default x509 private key matching --secure.tls.cert-key.cert-file.")
+
+	fs.StringVar(&s.ClientCert.ClientCA, "secure.client-cert.client-ca-file", s.ClientCert.ClientCA, ""+
+		"If set, HTTPS and gRPC clients presenting a certificate signed by one of the authorities "+
+		"in the client-ca-file can be authenticated with the identity of the certificate.")
+
+	fs.BoolVar(&s.ClientCert.Required, "secure.client-cert.required", s.ClientCert.Required, ""+
+		"If true, reject connections which do not present a client certificate signed by "+
+		"--secure.client-cert.client-ca-file.")
+
+	fs.StringVar(&s.ClientCert.Identity, "secure.client-cert.identity", s.ClientCert.Identity, ""+
+		"The field of the client certificate used as its identity, one of cn, dns, email and uri. "+
+		"Certificates carrying several values of the field are rejected.")
+
+	fs.StringSliceVar(&s.ClientCert.Users, "secure.client-cert.users", s.ClientCert.Users, ""+
+		"Usernames of the client certificate identities, as <identity>=<username> entries. "+
+		"Certificates whose identity is not listed are rejected by the mtls authentication strategy.")
 }
 
 // Complete fills in any fields not set that are required to have valid data.
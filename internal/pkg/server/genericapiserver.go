@@ -109,10 +109,17 @@ func (s *GenericAPIServer) Run() error {
 		// MaxHeaderBytes: 1 << 20,
 	}
 
+	// 配置了 client-ca-file 时开启客户端证书校验(mTLS)
+	tlsConfig, err := NewClientAuthTLSConfig(s.SecureServingInfo.ClientCA, s.SecureServingInfo.RequireClientCert)
+	if err != nil {
+		return err
+	}
+
 	// For scalability, use custom HTTPS configuration mode here
 	s.secureServer = &http.Server{
-		Addr:    s.SecureServingInfo.Address(),
-		Handler: s,
+		Addr:      s.SecureServingInfo.Address(),
+		Handler:   s,
+		TLSConfig: tlsConfig,
 		// ReadTimeout:    10 * time.Second,
 		// WriteTimeout:   10 * time.Second,
 		// MaxHeaderBytes: 1 << 20,
@@ -0,0 +1,119 @@
+// Package policylint is the command line tool to lint ladon policies before they are saved to iam-apiserver.
+package policylint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+	"github.com/nico612/iam-demo/internal/policylint/options"
+	"github.com/nico612/iam-demo/pkg/app"
+)
+
+const commandDesc = `IAM policy lint analyzes a set of ladon policies and reports the issues found, like invalid
+regular expressions in templates, allow policies shadowed by broader deny policies, overly broad wildcards,
+duplicate policies and unknown condition types.
+
+It runs the same checks as the lint endpoint of iam-apiserver, and exits with an error when any error
+finding is reported.`
+
+// NewApp creates an App object with default parameters.
+func NewApp(basename string) *app.App {
+	opts := options.NewOptions()
+	application := app.NewApp("IAM policy linter",
+		basename,
+		app.WithOptions(opts),
+		app.WithDescription(commandDesc),
+		app.WithDefaultValidArgs(),
+		app.WithNoConfig(),
+		app.WithSilence(),
+		app.WithRunFunc(run(opts)),
+	)
+
+	return application
+}
+
+func run(opts *options.Options) app.RunFunc {
+	return func(basename string) error {
+		linter, err := authorization.NewLinter(opts.LintOptions.Severity)
+		if err != nil {
+			return err
+		}
+
+		policies, err := readPolicies(opts.File)
+		if err != nil {
+			return err
+		}
+
+		result := linter.Lint(policies)
+		if err := printResult(os.Stdout, result, opts.Output); err != nil {
+			return err
+		}
+
+		if result.Errors > 0 {
+			return fmt.Errorf("%d errors found", result.Errors)
+		}
+
+		return nil
+	}
+}
+
+// readPolicies reads the policies from the file, which holds a JSON array of policies or a single policy.
+func readPolicies(file string) ([]*authorization.LintPolicy, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+
+	var policies []*authorization.LintPolicy
+	if bytes.HasPrefix(data, []byte("[")) {
+		err = json.Unmarshal(data, &policies)
+	} else {
+		policy := &authorization.LintPolicy{}
+		err = json.Unmarshal(data, policy)
+		policies = append(policies, policy)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode policies from %s: %w", file, err)
+	}
+
+	for i, policy := range policies {
+		if policy == nil {
+			return nil, fmt.Errorf("policy %d of %s is null", i, file)
+		}
+	}
+
+	return policies, nil
+}
+
+func printResult(w io.Writer, result *authorization.LintResult, output string) error {
+	if output == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(result)
+	}
+
+	for _, finding := range result.Findings {
+		if _, err := fmt.Fprintln(w, finding.String()); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d errors, %d warnings\n", result.Errors, result.Warnings)
+
+	return err
+}
@@ -0,0 +1,63 @@
+// Package options contains flags and options for initializing iam-policy-lint.
+package options
+
+import (
+	"encoding/json"
+	"fmt"
+
+	cliflag "github.com/marmotedu/component-base/pkg/cli/flag"
+
+	"github.com/nico612/iam-demo/internal/pkg/authorization"
+)
+
+// Options runs an iam policy linter.
+type Options struct {
+	File        string                     `json:"file"        mapstructure:"file"`
+	Output      string                     `json:"output"      mapstructure:"output"`
+	LintOptions *authorization.LintOptions `json:"policy-lint" mapstructure:"policy-lint"`
+}
+
+// NewOptions creates a new Options object with default parameters.
+func NewOptions() *Options {
+	return &Options{
+		File:        "-",
+		Output:      "text",
+		LintOptions: authorization.NewLintOptions(),
+	}
+}
+
+// Flags returns flags for iam-policy-lint by section name.
+func (o *Options) Flags() (fss cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("lint")
+	fs.StringVarP(&o.File, "file", "f", o.File, ""+
+		"File of the policies to lint, a JSON array of ladon policies or a single policy. "+
+		"Use - to read from the standard input.")
+	fs.StringVarP(&o.Output, "output", "o", o.Output, "Output format of the findings, one of text and json.")
+
+	o.LintOptions.AddFlags(fss.FlagSet("policy lint"))
+
+	return fss
+}
+
+// Validate checks Options and return a slice of found errs.
+func (o *Options) Validate() []error {
+	var errs []error
+
+	if o.File == "" {
+		errs = append(errs, fmt.Errorf("--file must be specified"))
+	}
+
+	if o.Output != "text" && o.Output != "json" {
+		errs = append(errs, fmt.Errorf("--output %s must be one of text and json", o.Output))
+	}
+
+	errs = append(errs, o.LintOptions.Validate()...)
+
+	return errs
+}
+
+func (o *Options) String() string {
+	data, _ := json.Marshal(o)
+
+	return string(data)
+}
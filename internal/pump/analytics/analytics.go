@@ -16,6 +16,8 @@ type AnalyticsRecord struct {
 	Request    string    `json:"request"`
 	Policies   string    `json:"policies"`
 	Deciders   string    `json:"deciders"`
+	ReasonCode int       `json:"reasonCode"`
+	Policy     string    `json:"policy"`
 	ExpireAt   time.Time `json:"expireAt"   bson:"expireAt"`
 }
 
@@ -0,0 +1,134 @@
+package pumps
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/marmotedu/errors"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/nico612/iam-demo/internal/pump/analytics"
+	"github.com/nico612/iam-demo/pkg/log"
+)
+
+// csvDateLayout is the layout of the date in the csv file names, one file is written per day.
+const csvDateLayout = "2006-01-02"
+
+// now returns the current time, it decides the csv file the records are written to.
+var now = time.Now
+
+// CSVConf defines csv specific options.
+type CSVConf struct {
+	// CSVDir is the directory of the csv files, it is created if not exist.
+	CSVDir string `json:"csv_dir" mapstructure:"csv_dir"`
+}
+
+// CSVPump defines a csv pump with csv specific options and common options.
+type CSVPump struct {
+	csvConf *CSVConf
+	// 上一次写入超时后可能仍在写文件，避免和下一次写入交错
+	mu sync.Mutex
+	CommonPumpConfig
+}
+
+// New create a csv pump instance.
+func (c *CSVPump) New() Pump {
+	newPump := CSVPump{}
+
+	return &newPump
+}
+
+// GetName returns the csv pump name.
+func (c *CSVPump) GetName() string {
+	return "CSV Pump"
+}
+
+// Init initialize the csv pump instance and creates the csv directory.
+func (c *CSVPump) Init(conf interface{}) error {
+	c.csvConf = &CSVConf{}
+	if err := mapstructure.Decode(conf, c.csvConf); err != nil {
+		return errors.Wrap(err, "failed to decode configuration")
+	}
+
+	if c.csvConf.CSVDir == "" {
+		return errors.New("csv_dir is required")
+	}
+
+	if err := os.MkdirAll(c.csvConf.CSVDir, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create csv directory")
+	}
+
+	log.Debugf("CSV Dir: %s", c.csvConf.CSVDir)
+
+	return nil
+}
+
+// WriteData appends the records to the csv file of the day, a new file starts with a header row. The file is
+// synced to disk before returning, the writing stops when the context is done.
+func (c *CSVPump) WriteData(ctx context.Context, data []interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "csv writing canceled")
+	}
+
+	fname := filepath.Join(c.csvConf.CSVDir, now().Format(csvDateLayout)+".csv")
+	outfile, err := os.OpenFile(fname, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open csv file")
+	}
+	defer outfile.Close()
+
+	info, err := outfile.Stat()
+	if err != nil {
+		return errors.Wrap(err, "failed to stat csv file")
+	}
+
+	log.Debugf("Writing %d records to csv file: %s", len(data), fname)
+
+	writer := csv.NewWriter(outfile)
+
+	// 文件为空说明是当天的第一次写入，先写表头
+	if info.Size() == 0 {
+		header := &analytics.AnalyticsRecord{}
+		if err := writer.Write(header.GetFieldNames()); err != nil {
+			return errors.Wrap(err, "failed to write csv header")
+		}
+	}
+
+	for _, item := range data {
+		if err := ctx.Err(); err != nil {
+			break
+		}
+
+		decoded, ok := item.(analytics.AnalyticsRecord)
+		if !ok {
+			continue
+		}
+
+		if err := writer.Write(decoded.GetLineValues()); err != nil {
+			return errors.Wrap(err, "failed to write csv record")
+		}
+	}
+
+	// 超时时也刷新已写入的记录，保证文件中的行是完整的
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return errors.Wrap(err, "failed to flush csv file")
+	}
+
+	if err := outfile.Sync(); err != nil {
+		return errors.Wrap(err, "failed to sync csv file")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "csv writing canceled")
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package pumps
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nico612/iam-demo/internal/pump/analytics"
+)
+
+func TestCSVPump_WriteData(t *testing.T) {
+	day := func(value string) time.Time {
+		at, err := time.ParseInLocation(time.RFC3339, value, time.Local)
+		require.NoError(t, err)
+
+		return at
+	}
+	record := func(username string) interface{} {
+		return analytics.AnalyticsRecord{Username: username, Effect: "allow"}
+	}
+
+	type write struct {
+		at   time.Time
+		data []interface{}
+	}
+
+	tests := []struct {
+		name   string
+		writes []write
+		// want 是每个文件中除表头外的用户名
+		want map[string][]string
+	}{
+		{
+			name: "one header per file",
+			writes: []write{
+				{day("2026-10-19T08:00:00Z"), []interface{}{record("alice"), record("bob")}},
+				{day("2026-10-19T20:00:00Z"), []interface{}{record("carol")}},
+			},
+			want: map[string][]string{"2026-10-19.csv": {"alice", "bob", "carol"}},
+		},
+		{
+			name: "daily rotation",
+			writes: []write{
+				{day("2026-10-19T23:59:59Z"), []interface{}{record("alice")}},
+				{day("2026-10-20T00:00:00Z"), []interface{}{record("bob")}},
+				{day("2026-10-20T12:00:00Z"), []interface{}{record("carol")}},
+			},
+			want: map[string][]string{
+				"2026-10-19.csv": {"alice"},
+				"2026-10-20.csv": {"bob", "carol"},
+			},
+		},
+		{
+			name: "skip the items which are not records",
+			writes: []write{
+				{day("2026-10-19T08:00:00Z"), []interface{}{"alice", record("bob"), 42}},
+			},
+			want: map[string][]string{"2026-10-19.csv": {"bob"}},
+		},
+	}
+
+	header := (&analytics.AnalyticsRecord{}).GetFieldNames()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { now = time.Now })
+
+			dir := t.TempDir()
+			pump := (&CSVPump{}).New()
+			require.NoError(t, pump.Init(map[string]interface{}{"csv_dir": dir}))
+
+			for _, w := range tt.writes {
+				at := w.at
+				now = func() time.Time { return at }
+
+				require.NoError(t, pump.WriteData(context.Background(), w.data))
+			}
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, entries, len(tt.want))
+
+			for name, usernames := range tt.want {
+				// 写入返回后文件已经落盘，重新打开即可读到所有记录
+				f, err := os.Open(filepath.Join(dir, name))
+				require.NoError(t, err)
+
+				rows, err := csv.NewReader(f).ReadAll()
+				f.Close()
+				require.NoError(t, err)
+				require.NotEmpty(t, rows)
+
+				assert.Equal(t, header, rows[0])
+
+				got := []string{}
+				for _, row := range rows[1:] {
+					assert.NotEqual(t, header, row)
+					got = append(got, row[1])
+				}
+
+				assert.Equal(t, usernames, got)
+			}
+		})
+	}
+}
+
+func TestCSVPump_WriteData_Canceled(t *testing.T) {
+	dir := t.TempDir()
+	pump := (&CSVPump{}).New()
+	require.NoError(t, pump.Init(map[string]interface{}{"csv_dir": dir}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := pump.WriteData(ctx, []interface{}{analytics.AnalyticsRecord{Username: "alice"}})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -6,5 +6,6 @@ var availablePumps map[string]Pump
 func init() {
 	availablePumps = make(map[string]Pump)
 
+	availablePumps["csv"] = &CSVPump{}
 	availablePumps["mongo"] = &MongoPump{}
 }
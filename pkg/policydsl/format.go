@@ -0,0 +1,176 @@
+package policydsl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ory/ladon"
+)
+
+// Format decompiles a ladon policy to the DSL, the conditions are written in the order of their keys.
+// The id and the meta of the policy are not part of the DSL.
+func Format(policy *ladon.DefaultPolicy) (string, error) {
+	if policy.Effect != ladon.AllowAccess && policy.Effect != ladon.DenyAccess {
+		return "", fmt.Errorf("invalid effect `%s`", policy.Effect)
+	}
+
+	for _, list := range []struct {
+		field  string
+		values []string
+	}{{"subjects", policy.Subjects}, {"actions", policy.Actions}, {"resources", policy.Resources}} {
+		if len(list.values) == 0 {
+			return "", fmt.Errorf("policy has no %s", list.field)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s %s %s %s",
+		policy.Effect, formatValues(policy.Subjects),
+		keywordTo, formatValues(policy.Actions),
+		keywordOn, formatValues(policy.Resources))
+
+	keys := make([]string, 0, len(policy.Conditions))
+	for key := range policy.Conditions {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for i, key := range keys {
+		condition, err := formatCondition(policy.Conditions[key])
+		if err != nil {
+			return "", fmt.Errorf("condition `%s`: %w", key, err)
+		}
+
+		keyword := keywordAnd
+		if i == 0 {
+			keyword = keywordWhen
+		}
+
+		fmt.Fprintf(&b, "\n  %s %s %s", keyword, formatKey(key), condition)
+	}
+
+	if policy.Description != "" {
+		fmt.Fprintf(&b, "\n  %s %s", keywordBecause, strconv.Quote(policy.Description))
+	}
+
+	return b.String(), nil
+}
+
+func formatValues(values []string) string {
+	formatted := make([]string, 0, len(values))
+	for _, value := range values {
+		formatted = append(formatted, formatValue(value))
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// formatValue writes the value as a word when it reads the same, with the templates matching anything
+// written as `*`, or quotes it otherwise.
+func formatValue(value string) string {
+	if !isPlainWord(value, false) {
+		return strconv.Quote(value)
+	}
+
+	var b strings.Builder
+	depth, start := 0, 0
+	for i, r := range value {
+		switch r {
+		case '<':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case '>':
+			depth--
+			if depth == 0 {
+				template := value[start : i+1]
+				if template == matchAll {
+					template = wildcard
+				}
+
+				b.WriteString(template)
+			}
+
+			continue
+		}
+
+		if depth == 0 {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// formatKey writes the condition key as a word when it reads the same, or quotes it otherwise.
+func formatKey(key string) string {
+	if !isPlainWord(key, true) {
+		return strconv.Quote(key)
+	}
+
+	return key
+}
+
+// isPlainWord reports whether the text can be written as a word. Outside of the balanced templates, a word
+// can not hold the characters ending a word, or a `*` which would be read as a wildcard. Literal words
+// can not hold templates at all.
+func isPlainWord(text string, literal bool) bool {
+	if text == "" || keywords[text] || strings.HasPrefix(text, "{") {
+		return false
+	}
+
+	depth := 0
+	for _, r := range text {
+		switch {
+		case r == '<':
+			if literal {
+				return false
+			}
+			depth++
+		case r == '>':
+			if depth--; depth < 0 {
+				return false
+			}
+		case depth > 0:
+		case unicode.IsSpace(r) || r == ',' || r == '"' || r == '#' || r == '*':
+			return false
+		}
+	}
+
+	return depth == 0
+}
+
+func formatCondition(condition ladon.Condition) (string, error) {
+	switch c := condition.(type) {
+	case *ladon.CIDRCondition:
+		if _, _, err := net.ParseCIDR(c.CIDR); err == nil {
+			return operatorIn + " " + c.CIDR, nil
+		}
+	case *ladon.StringEqualCondition:
+		return operatorIs + " " + strconv.Quote(c.Equals), nil
+	case *ladon.EqualsSubjectCondition:
+		return operatorIs + " " + valueSubject, nil
+	case *ladon.BooleanCondition:
+		return operatorIs + " " + strconv.FormatBool(c.BooleanValue), nil
+	case *ladon.StringMatchCondition:
+		return operatorMatches + " " + strconv.Quote(c.Matches), nil
+	}
+
+	options, err := json.Marshal(condition)
+	if err != nil {
+		return "", err
+	}
+
+	if string(options) == "{}" || string(options) == "null" {
+		return operatorSatisfies + " " + condition.GetName(), nil
+	}
+
+	return operatorSatisfies + " " + condition.GetName() + " " + string(options), nil
+}
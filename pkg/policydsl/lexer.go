@@ -0,0 +1,255 @@
+package policydsl
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenComma
+	tokenJSON
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenWord:
+		return "word"
+	case tokenString:
+		return "string"
+	case tokenComma:
+		return "`,`"
+	case tokenJSON:
+		return "JSON object"
+	default:
+		return "end of policy"
+	}
+}
+
+// token is a lexical token of the policy DSL. The text of a string token is unquoted.
+type token struct {
+	kind   tokenKind
+	text   string
+	line   int
+	column int
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenWord:
+		return "`" + t.text + "`"
+	case tokenString:
+		return strconv.Quote(t.text)
+	default:
+		return t.kind.String()
+	}
+}
+
+// lexer splits the source into tokens, the lines and columns are counted from 1 in runes.
+type lexer struct {
+	src    []rune
+	pos    int
+	line   int
+	column int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: []rune(src), line: 1, column: 1}
+}
+
+func (l *lexer) peek() rune {
+	if l.pos >= len(l.src) {
+		return 0
+	}
+
+	return l.src[l.pos]
+}
+
+func (l *lexer) next() rune {
+	r := l.src[l.pos]
+	l.pos++
+
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+
+	return r
+}
+
+// skip skips the spaces and the comments, a comment starts with `#` and ends at the end of the line.
+func (l *lexer) skip() {
+	for l.pos < len(l.src) {
+		r := l.peek()
+		switch {
+		case unicode.IsSpace(r):
+			l.next()
+		case r == '#':
+			for l.pos < len(l.src) && l.peek() != '\n' {
+				l.next()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// tokens returns all the tokens of the source, ending with an EOF token.
+func (l *lexer) tokens() ([]token, error) {
+	var tokens []token
+	for {
+		t, err := l.token()
+		if err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, t)
+		if t.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) token() (token, error) {
+	l.skip()
+
+	t := token{line: l.line, column: l.column}
+	if l.pos >= len(l.src) {
+		return t, nil
+	}
+
+	switch l.peek() {
+	case ',':
+		l.next()
+		t.kind, t.text = tokenComma, ","
+
+		return t, nil
+	case '"':
+		text, err := l.scanString()
+		t.kind, t.text = tokenString, text
+
+		return t, err
+	case '{':
+		text, err := l.scanJSON()
+		t.kind, t.text = tokenJSON, text
+
+		return t, err
+	}
+
+	text, err := l.scanWord()
+	t.kind, t.text = tokenWord, text
+
+	return t, err
+}
+
+// scanString scans a double-quoted string with the escapes of Go string literals.
+func (l *lexer) scanString() (string, error) {
+	line, column := l.line, l.column
+	var b strings.Builder
+	b.WriteRune(l.next())
+
+	for {
+		if l.pos >= len(l.src) || l.peek() == '\n' {
+			return "", newSyntaxError(line, column, "unterminated string")
+		}
+
+		r := l.next()
+		b.WriteRune(r)
+
+		if r == '\\' && l.pos < len(l.src) {
+			b.WriteRune(l.next())
+
+			continue
+		}
+
+		if r == '"' {
+			break
+		}
+	}
+
+	text, err := strconv.Unquote(b.String())
+	if err != nil {
+		return "", newSyntaxError(line, column, "invalid string %s", b.String())
+	}
+
+	return text, nil
+}
+
+// scanJSON scans a JSON object, the braces in the JSON strings are ignored.
+func (l *lexer) scanJSON() (string, error) {
+	line, column := l.line, l.column
+	start := l.pos
+	depth, inString := 0, false
+
+	for l.pos < len(l.src) {
+		r := l.next()
+		switch {
+		case inString && r == '\\':
+			if l.pos < len(l.src) {
+				l.next()
+			}
+		case r == '"':
+			inString = !inString
+		case inString:
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+		}
+
+		if depth == 0 {
+			text := string(l.src[start:l.pos])
+			if !json.Valid([]byte(text)) {
+				return "", newSyntaxError(line, column, "invalid JSON object")
+			}
+
+			return text, nil
+		}
+	}
+
+	return "", newSyntaxError(line, column, "unterminated JSON object")
+}
+
+// scanWord scans a word, which ends at a space, a comma, a quote or a comment. The `<...>` templates in a word
+// are kept as they are, they may contain any character but balanced `<` and `>`, like the ladon templates.
+func (l *lexer) scanWord() (string, error) {
+	start := l.pos
+	depth := 0
+	line, column := l.line, l.column
+
+	for l.pos < len(l.src) {
+		r := l.peek()
+		if depth == 0 && (unicode.IsSpace(r) || r == ',' || r == '"' || r == '#') {
+			break
+		}
+
+		switch r {
+		case '<':
+			if depth == 0 {
+				line, column = l.line, l.column
+			}
+			depth++
+		case '>':
+			if depth == 0 {
+				return "", newSyntaxError(l.line, l.column, "unexpected `>` outside of a template")
+			}
+			depth--
+		}
+
+		l.next()
+	}
+
+	if depth > 0 {
+		return "", newSyntaxError(line, column, "unterminated template")
+	}
+
+	return string(l.src[start:l.pos]), nil
+}
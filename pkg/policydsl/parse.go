@@ -0,0 +1,281 @@
+package policydsl
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+
+	"github.com/ory/ladon"
+)
+
+// parser parses the tokens of one policy.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse compiles a policy written in the DSL to a ladon policy. The id of the policy is not part of the DSL
+// and is left empty. The errors are of type *SyntaxError.
+func Parse(src string) (*ladon.DefaultPolicy, error) {
+	tokens, err := newLexer(src).tokens()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	return p.policy()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+// isKeyword reports whether the next token is the keyword.
+func (p *parser) isKeyword(keyword string) bool {
+	t := p.peek()
+
+	return t.kind == tokenWord && t.text == keyword
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.isKeyword(keyword) {
+		return unexpected(p.peek(), "`"+keyword+"`")
+	}
+
+	p.next()
+
+	return nil
+}
+
+// policy := effect values "to" values "on" values [ "when" condition { "and" condition } ] [ "because" string ].
+func (p *parser) policy() (*ladon.DefaultPolicy, error) {
+	policy := &ladon.DefaultPolicy{}
+
+	switch t := p.next(); {
+	case t.kind == tokenWord && (t.text == ladon.AllowAccess || t.text == ladon.DenyAccess):
+		policy.Effect = t.text
+	default:
+		return nil, unexpected(t, "`allow` or `deny`")
+	}
+
+	var err error
+	if policy.Subjects, err = p.values(); err != nil {
+		return nil, err
+	}
+
+	if err := p.expectKeyword(keywordTo); err != nil {
+		return nil, err
+	}
+
+	if policy.Actions, err = p.values(); err != nil {
+		return nil, err
+	}
+
+	if err := p.expectKeyword(keywordOn); err != nil {
+		return nil, err
+	}
+
+	if policy.Resources, err = p.values(); err != nil {
+		return nil, err
+	}
+
+	if p.isKeyword(keywordWhen) {
+		p.next()
+
+		policy.Conditions = ladon.Conditions{}
+		for {
+			if err := p.condition(policy.Conditions); err != nil {
+				return nil, err
+			}
+
+			if !p.isKeyword(keywordAnd) {
+				break
+			}
+
+			p.next()
+		}
+	}
+
+	if p.isKeyword(keywordBecause) {
+		p.next()
+
+		t := p.next()
+		if t.kind != tokenString {
+			return nil, unexpected(t, "a quoted description")
+		}
+
+		policy.Description = t.text
+	}
+
+	if t := p.next(); t.kind != tokenEOF {
+		return nil, unexpected(t, "end of policy")
+	}
+
+	return policy, nil
+}
+
+// values := value { "," value }.
+func (p *parser) values() ([]string, error) {
+	var values []string
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+		if p.peek().kind != tokenComma {
+			return values, nil
+		}
+
+		p.next()
+	}
+}
+
+// value is a word, whose `*` outside of the templates matches anything, or a quoted string taken literally.
+func (p *parser) value() (string, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenString:
+		return t.text, nil
+	case t.kind == tokenWord && !keywords[t.text]:
+		return expandWildcards(t.text), nil
+	default:
+		return "", unexpected(t, "a value")
+	}
+}
+
+// key is the request context key of a condition, a word or a quoted string taken literally.
+func (p *parser) key() (token, error) {
+	t := p.next()
+	if t.kind == tokenString || (t.kind == tokenWord && !keywords[t.text]) {
+		return t, nil
+	}
+
+	return t, unexpected(t, "a condition key")
+}
+
+// condition := key ( "in" cidr | "is" ( "subject" | "true" | "false" | string ) | "matches" string |
+// "satisfies" type [ json ] ).
+func (p *parser) condition(conditions ladon.Conditions) error {
+	key, err := p.key()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := conditions[key.text]; ok {
+		return newSyntaxError(key.line, key.column, "duplicate condition on %s", key.describe())
+	}
+
+	op := p.next()
+	if op.kind != tokenWord {
+		return unexpected(op, "`in`, `is`, `matches` or `satisfies`")
+	}
+
+	var condition ladon.Condition
+	switch op.text {
+	case operatorIn:
+		t := p.next()
+		if t.kind != tokenWord {
+			return unexpected(t, "a CIDR")
+		}
+
+		if _, _, err := net.ParseCIDR(t.text); err != nil {
+			return newSyntaxError(t.line, t.column, "invalid CIDR %s", t.describe())
+		}
+
+		condition = &ladon.CIDRCondition{CIDR: t.text}
+	case operatorIs:
+		t := p.next()
+		switch {
+		case t.kind == tokenString:
+			condition = &ladon.StringEqualCondition{Equals: t.text}
+		case t.kind == tokenWord && t.text == valueSubject:
+			condition = &ladon.EqualsSubjectCondition{}
+		case t.kind == tokenWord && (t.text == valueTrue || t.text == valueFalse):
+			condition = &ladon.BooleanCondition{BooleanValue: t.text == valueTrue}
+		default:
+			return unexpected(t, "`subject`, `true`, `false` or a quoted string")
+		}
+	case operatorMatches:
+		t := p.next()
+		if t.kind != tokenString {
+			return unexpected(t, "a quoted regular expression")
+		}
+
+		condition = &ladon.StringMatchCondition{Matches: t.text}
+	case operatorSatisfies:
+		if condition, err = p.customCondition(); err != nil {
+			return err
+		}
+	default:
+		return unexpected(op, "`in`, `is`, `matches` or `satisfies`")
+	}
+
+	conditions[key.text] = condition
+
+	return nil
+}
+
+// customCondition parses a condition of any registered type, with its options as a JSON object.
+func (p *parser) customCondition() (ladon.Condition, error) {
+	t := p.next()
+	if t.kind != tokenWord {
+		return nil, unexpected(t, "a condition type")
+	}
+
+	factory, ok := ladon.ConditionFactories[t.text]
+	if !ok {
+		return nil, newSyntaxError(t.line, t.column, "unknown condition type %s", t.describe())
+	}
+
+	condition := factory()
+	if p.peek().kind == tokenJSON {
+		options := p.next()
+		if err := json.Unmarshal([]byte(options.text), condition); err != nil {
+			return nil, newSyntaxError(options.line, options.column, "invalid options of %s: %s", t.text, err.Error())
+		}
+	}
+
+	return condition, nil
+}
+
+// expandWildcards replaces the `*` outside of the templates with the template matching anything.
+func expandWildcards(word string) string {
+	if !strings.Contains(word, wildcard) {
+		return word
+	}
+
+	var b strings.Builder
+	depth := 0
+	for _, r := range word {
+		switch {
+		case r == '<':
+			depth++
+		case r == '>':
+			depth--
+		case r == '*' && depth == 0:
+			b.WriteString(matchAll)
+
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func unexpected(t token, expected string) *SyntaxError {
+	return newSyntaxError(t.line, t.column, "expected %s, found %s", expected, t.describe())
+}
@@ -0,0 +1,73 @@
+// Package policydsl implements a human-readable text format of ladon policies. A policy reads like
+//
+//	allow users:<alice|bob> to read, write on resources:articles:*
+//	  when ip in 10.0.0.0/8
+//	  and owner is subject
+//	  because "editors manage the articles from the office"
+//
+// The subjects, actions and resources are comma-separated values. A `*` in a value matches anything, and
+// `<...>` templates are ladon regular expressions. Quoted values are taken literally, they are required for
+// the values with spaces, commas, `*`, `#` or the keywords. The conditions are keyed by the request context key:
+//
+//	<key> in <cidr>                      CIDRCondition
+//	<key> is "<string>"                  StringEqualCondition
+//	<key> is subject                     EqualsSubjectCondition
+//	<key> is true | false                BooleanCondition
+//	<key> matches "<regexp>"             StringMatchCondition
+//	<key> satisfies <type> [<options>]   any registered condition, with the options as a JSON object
+//
+// Comments start with `#` and end at the end of the line.
+package policydsl
+
+import (
+	"fmt"
+
+	"github.com/ory/ladon"
+)
+
+const (
+	keywordTo      = "to"
+	keywordOn      = "on"
+	keywordWhen    = "when"
+	keywordAnd     = "and"
+	keywordBecause = "because"
+
+	operatorIn        = "in"
+	operatorIs        = "is"
+	operatorMatches   = "matches"
+	operatorSatisfies = "satisfies"
+
+	valueSubject = "subject"
+	valueTrue    = "true"
+	valueFalse   = "false"
+
+	wildcard = "*"
+	matchAll = "<.*>"
+)
+
+// keywords can not be used as unquoted values or condition keys.
+var keywords = map[string]bool{
+	ladon.AllowAccess: true,
+	ladon.DenyAccess:  true,
+	keywordTo:         true,
+	keywordOn:         true,
+	keywordWhen:       true,
+	keywordAnd:        true,
+	keywordBecause:    true,
+}
+
+// SyntaxError is an error in the source of a policy, the line and column are counted from 1.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func newSyntaxError(line, column int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Error implements the error interface.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
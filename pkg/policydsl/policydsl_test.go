@@ -0,0 +1,119 @@
+package policydsl_test
+
+import (
+	"testing"
+
+	"github.com/ory/ladon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nico612/iam-demo/pkg/policydsl"
+)
+
+func Test_Parse(t *testing.T) {
+	policy, err := policydsl.Parse(`allow users:<alice> to read,write on resources:articles:* when ip in 10.0.0.0/8`)
+	require.NoError(t, err)
+
+	assert.Equal(t, &ladon.DefaultPolicy{
+		Subjects:   []string{"users:<alice>"},
+		Effect:     ladon.AllowAccess,
+		Resources:  []string{"resources:articles:<.*>"},
+		Actions:    []string{"read", "write"},
+		Conditions: ladon.Conditions{"ip": &ladon.CIDRCondition{CIDR: "10.0.0.0/8"}},
+	}, policy)
+}
+
+func Test_Parse_Conditions(t *testing.T) {
+	policy, err := policydsl.Parse(`
+# editors of the articles
+deny "users:*", users:<[a-z]+ [a-z]+> to delete on resources:<.*>:*
+  when owner is subject
+  and region is "cn-east"   # region of the request
+  and "trusted client" is true
+  and user-agent matches "curl/.*"
+  and groups satisfies StringPairsEqualCondition
+  because "articles can not be deleted"`)
+	require.NoError(t, err)
+
+	assert.Equal(t, ladon.DenyAccess, policy.Effect)
+	assert.Equal(t, []string{"users:*", "users:<[a-z]+ [a-z]+>"}, policy.Subjects)
+	assert.Equal(t, []string{"resources:<.*>:<.*>"}, policy.Resources)
+	assert.Equal(t, "articles can not be deleted", policy.Description)
+	assert.Equal(t, ladon.Conditions{
+		"owner":          &ladon.EqualsSubjectCondition{},
+		"region":         &ladon.StringEqualCondition{Equals: "cn-east"},
+		"trusted client": &ladon.BooleanCondition{BooleanValue: true},
+		"user-agent":     &ladon.StringMatchCondition{Matches: "curl/.*"},
+		"groups":         &ladon.StringPairsEqualCondition{},
+	}, policy.Conditions)
+}
+
+func Test_Parse_Errors(t *testing.T) {
+	tests := []struct {
+		src    string
+		line   int
+		column int
+		msg    string
+	}{
+		{"permit a to b on c", 1, 1, "expected `allow` or `deny`, found `permit`"},
+		{"allow a to b", 1, 13, "expected `on`, found end of policy"},
+		{"allow a,\n  to b on c", 2, 3, "expected a value, found `to`"},
+		{"allow a to b on c\nwhen ip in 10.0.0.300/8", 2, 12, "invalid CIDR `10.0.0.300/8`"},
+		{"allow a to b on c when x satisfies NoSuchCondition", 1, 36, "unknown condition type `NoSuchCondition`"},
+		{"allow a to b on c when x is 1", 1, 29, "expected `subject`, `true`, `false` or a quoted string, found `1`"},
+		{"allow a to b on c when x is true and x is false", 1, 38, "duplicate condition on `x`"},
+		{"allow users:<[a-z to b on c", 1, 13, "unterminated template"},
+		{"allow a to b on \"c", 1, 17, "unterminated string"},
+		{"allow a to b on c because d", 1, 27, "expected a quoted description, found `d`"},
+	}
+
+	for _, tt := range tests {
+		_, err := policydsl.Parse(tt.src)
+
+		var syntaxErr *policydsl.SyntaxError
+		require.ErrorAs(t, err, &syntaxErr, tt.src)
+		assert.Equal(t, tt.line, syntaxErr.Line, tt.src)
+		assert.Equal(t, tt.column, syntaxErr.Column, tt.src)
+		assert.Equal(t, tt.msg, syntaxErr.Msg, tt.src)
+	}
+}
+
+func Test_Format(t *testing.T) {
+	policy := &ladon.DefaultPolicy{
+		ID:          "articles",
+		Description: `editors manage the "articles"`,
+		Subjects:    []string{"users:<alice|bob>", "to", "users:a*b"},
+		Effect:      ladon.AllowAccess,
+		Resources:   []string{"resources:articles:<.*>", "resources:<.*>:<[0-9]+>"},
+		Actions:     []string{"read", "write"},
+		Conditions: ladon.Conditions{
+			"ip":    &ladon.CIDRCondition{CIDR: "10.0.0.0/8"},
+			"owner": &ladon.EqualsSubjectCondition{},
+			"pairs": &ladon.StringPairsEqualCondition{},
+			"deep":  &ladon.ResourceContainsCondition{},
+		},
+	}
+
+	src, err := policydsl.Format(policy)
+	require.NoError(t, err)
+	assert.Equal(t, `allow users:<alice|bob>, "to", "users:a*b" to read, write on resources:articles:*, resources:*:<[0-9]+>
+  when deep satisfies ResourceContainsCondition
+  and ip in 10.0.0.0/8
+  and owner is subject
+  and pairs satisfies StringPairsEqualCondition
+  because "editors manage the \"articles\""`, src)
+
+	parsed, err := policydsl.Parse(src)
+	require.NoError(t, err)
+
+	policy.ID = ""
+	assert.Equal(t, policy, parsed)
+}
+
+func Test_Format_Errors(t *testing.T) {
+	_, err := policydsl.Format(&ladon.DefaultPolicy{Effect: "maybe"})
+	assert.EqualError(t, err, "invalid effect `maybe`")
+
+	_, err = policydsl.Format(&ladon.DefaultPolicy{Effect: ladon.DenyAccess, Subjects: []string{"a"}})
+	assert.EqualError(t, err, "policy has no actions")
+}
@@ -1112,16 +1112,57 @@ func (r *RedisCluster) GetSet(keyName string) (map[string]string, error) {
 }
 
 // AddToSet add value to key set.
-func (r *RedisCluster) AddToSet(keyName, value string) {
+func (r *RedisCluster) AddToSet(keyName, value string) error {
 	log.Debugf("Pushing to raw key set: %s", keyName)
 	log.Debugf("Pushing to fixed key set: %s", r.fixKey(keyName))
 	if err := r.up(); err != nil {
-		return
+		return err
 	}
 	err := r.singleton().SAdd(r.fixKey(keyName), value).Err()
 	if err != nil {
 		log.Errorf("Error trying to append keys: %s", err.Error())
+
+		return err
 	}
+
+	return nil
+}
+
+// compareAndSwapScript sets the key to ARGV[2] with a ttl of ARGV[3] milliseconds only when it holds ARGV[1],
+// a ttl of 0 means no expiration.
+var compareAndSwapScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// CompareAndSwap atomically replaces the value of the key with newValue when it still holds oldValue,
+// and reports whether the value was replaced. Like SetKey, a zero timeout means no expiration.
+func (r *RedisCluster) CompareAndSwap(keyName, oldValue, newValue string, timeout time.Duration) (bool, error) {
+	if err := r.up(); err != nil {
+		return false, err
+	}
+
+	swapped, err := compareAndSwapScript.Run(
+		r.singleton(),
+		[]string{r.fixKey(keyName)},
+		oldValue,
+		newValue,
+		timeout.Milliseconds(),
+	).Int()
+	if err != nil {
+		log.Errorf("Error trying to compare and swap value: %s", err.Error())
+
+		return false, err
+	}
+
+	return swapped == 1, nil
 }
 
 // RemoveFromSet remove a value from key set.
@@ -47,7 +47,7 @@ type Handler interface {
 	SetRollingWindow(key string, per int64, val string, pipeline bool) (int, []interface{})
 	GetRollingWindow(key string, per int64, pipeline bool) (int, []interface{})
 	GetSet(string) (map[string]string, error)
-	AddToSet(string, string)
+	AddToSet(string, string) error
 	GetAndDeleteSet(string) []interface{}
 	RemoveFromSet(string, string)
 	DeleteScanMatch(string) bool
@@ -128,6 +128,7 @@ iam::golang::setup_platforms
 # If you update this list, please also update build/BUILD.
 readonly IAM_CLIENT_TARGETS=(
   iamctl
+  iam-policy-lint
 )
 readonly IAM_CLIENT_BINARIES=("${IAM_CLIENT_TARGETS[@]##*/}")
 